@@ -27,13 +27,13 @@ import (
 	"os/signal"
 
 	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/auth"
 	"github.com/wso2/openservicebroker-apim/pkg/apim"
 	"github.com/wso2/openservicebroker-apim/pkg/broker"
 	"github.com/wso2/openservicebroker-apim/pkg/client"
 	"github.com/wso2/openservicebroker-apim/pkg/config"
 	"github.com/wso2/openservicebroker-apim/pkg/db"
 	"github.com/wso2/openservicebroker-apim/pkg/log"
-	"github.com/wso2/openservicebroker-apim/pkg/model"
 	"github.com/wso2/openservicebroker-apim/pkg/token"
 )
 
@@ -43,9 +43,19 @@ const (
 	InfoMSGShutdownBroker        = "starting APIM Service Broker shutdown"
 	InfoMSGServerStart           = "starting APIM Service broker"
 	ErrMsgUnableToAddForeignKeys = "unable to add foreign keys"
+	ErrMsgUnableToMigrate        = "unable to migrate the database"
+	// CommandMigrate applies the pending schema migrations and exits.
+	CommandMigrate = "migrate"
 )
 
 func main() {
+	var command string
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	if command != "" && command != CommandMigrate {
+		log.HandleErrorAndExit("unknown command: "+command, nil)
+	}
 
 	// load configuration.
 	conf, err := config.Load()
@@ -63,7 +73,12 @@ func main() {
 	// Initialize DB.
 	db.Init(&conf.DB)
 	defer db.CloseDBCon()
-	setupTables()
+	if command == CommandMigrate {
+		migrateDatabase()
+		log.Info("database is migrated", nil)
+		return
+	}
+	setupTables(conf.DB.MigrateOnStartup)
 
 	// Initialize Token manager.
 	tManager := &token.PasswordRefreshTokenGrantManager{
@@ -73,7 +88,7 @@ func main() {
 		UserName:                         conf.APIM.Username,
 		Password:                         conf.APIM.Password,
 	}
-	tManager.Init([]string{token.ScopeSubscribe, token.ScopeAPIView})
+	tManager.Init([]string{token.ScopeSubscribe, token.ScopeAPIView, token.ScopeAPICreate, token.ScopeAPIPublish})
 
 	// Initialize API-M client.
 	apim.Init(tManager, conf.APIM)
@@ -83,8 +98,13 @@ func main() {
 		Password: conf.HTTP.Server.Auth.Password,
 	}
 	apimServiceBroker := &broker.APIM{}
-	apimServiceBroker.Init()
+	apimServiceBroker.Init(conf)
 	brokerAPI := brokerapi.New(apimServiceBroker, logger, brokerCreds)
+	router := http.NewServeMux()
+	adminAuth := auth.NewWrapper(brokerCreds.Username, brokerCreds.Password)
+	router.Handle(broker.ReconcilePath, adminAuth.Wrap(broker.ReconcileHandler()))
+	router.Handle(broker.RotateCredentialsPath, adminAuth.Wrap(broker.RotateCredentialsHandler()))
+	router.Handle("/", brokerAPI)
 
 	host := conf.HTTP.Server.Host
 	port := conf.HTTP.Server.Port
@@ -93,7 +113,7 @@ func main() {
 		Add("port", port)
 
 	server := http.Server{
-		Handler: brokerAPI,
+		Handler: router,
 		Addr:    host + ":" + port,
 	}
 
@@ -121,28 +141,28 @@ func main() {
 	<-idleConsClosed
 }
 
-// addForeignKeys configures foreign keys for Subscription table.
-func addForeignKeys() {
-	// With this foreign key mapping all the subscriptions are deleted respective once the service instance is deleted.
-	err := db.AddForeignKey(&model.Subscription{}, model.ServiceInstanceIDFieldName, model.ForeignKeyDestSVCInstanceID, "CASCADE",
-		"CASCADE")
-	if err != nil {
-		log.HandleErrorAndExit(ErrMsgUnableToAddForeignKeys, err)
+// setupTables applies the pending schema migrations if the migrations are run on startup, otherwise it checks that
+// there are no pending migrations. The process exits if there is an error or a pending migration.
+func setupTables(migrate bool) {
+	if migrate {
+		migrateDatabase()
+		return
 	}
-	// With this foreign key mapping  it is restricted to delete a bind of a existing service instance.
-	err = db.AddForeignKey(&model.Bind{}, model.ServiceInstanceIDFieldName, model.ForeignKeyDestSVCInstanceID, "RESTRICT",
-		"RESTRICT")
+	pending, err := db.PendingMigrations(migrations)
 	if err != nil {
-		log.HandleErrorAndExit(ErrMsgUnableToAddForeignKeys, err)
+		log.HandleErrorAndExit(ErrMsgUnableToMigrate, err)
+	}
+	if len(pending) > 0 {
+		log.HandleErrorAndExit(fmt.Sprintf("database has pending migrations %v, apply them with the %s command",
+			pending, CommandMigrate), nil)
 	}
 }
 
-// SetupTables creates the tables and add foreign keys.
-func setupTables() {
-	db.CreateTable(&model.ServiceInstance{})
-	db.CreateTable(&model.Subscription{})
-	db.CreateTable(&model.Bind{})
-	addForeignKeys()
+// migrateDatabase applies the pending schema migrations. The process exits if there is an error.
+func migrateDatabase() {
+	if err := db.Migrate(migrations); err != nil {
+		log.HandleErrorAndExit(ErrMsgUnableToMigrate, err)
+	}
 }
 
 // handleGracefulShutdown shutdown the server gracefully.
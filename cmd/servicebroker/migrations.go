@@ -0,0 +1,333 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/openservicebroker-apim/pkg/broker"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+// migrations are the versioned changes of the database schema in the order they are applied. An applied migration
+// must not be changed, a schema change is added as a new migration. The migrations describe the tables with their own
+// structs instead of the models, which change with the later migrations.
+var migrations = []db.Migration{
+	{Version: 1, Description: "create the service instance, subscription and bind tables", Migrate: createBaseTables},
+	{Version: 2, Description: "create the operation table", Migrate: createOperationTable},
+	{Version: 3, Description: "add the service and plan of the service instances", Migrate: addInstancePlan},
+	{Version: 4, Description: "add the parameters of the binds", Migrate: addBindParameters},
+	{Version: 5, Description: "add the Applications of the binds", Migrate: addBindApplications},
+	{Version: 6, Description: "add the throttling policy of the service instances", Migrate: addInstanceThrottlingPolicy},
+	{Version: 7, Description: "add the tier of the subscriptions", Migrate: addSubscriptionTier},
+	{Version: 8, Description: "add the API ID of the subscriptions", Migrate: addSubscriptionAPIID},
+	{Version: 9, Description: "add the version selector of the subscriptions", Migrate: addSubscriptionSelector},
+	{Version: 10, Description: "add the platform of the service instances", Migrate: addInstancePlatform},
+	{Version: 11, Description: "create the API instance table", Migrate: createAPIInstanceTable},
+	{Version: 12, Description: "create the subscription instance table", Migrate: createSubscriptionInstanceTable},
+	{Version: 13, Description: "add the adopted Applications of the service instances", Migrate: addInstanceAdoption},
+	{Version: 14, Description: "add the drift of the service instances", Migrate: addInstanceDrift},
+	{Version: 15, Description: "create the saga tables", Migrate: createSagaTables},
+	{Version: 16, Description: "create the instance lock table", Migrate: createInstanceLockTable},
+	{Version: 17, Description: "add the secret rotation time of the service instances", Migrate: addInstanceRotation},
+	{Version: 18, Description: "add the key parameters of the service instances and binds", Migrate: addKeyParameters},
+	{Version: 19, Description: "add the credentials version of the binds", Migrate: addBindCredentialsVersion},
+	{Version: 20, Description: "add the credential template of the binds", Migrate: addBindCredentialTemplate},
+	{Version: 21, Description: "add the request hash of the operations", Migrate: addOperationRequestHash},
+}
+
+// createBaseTables creates the tables of the broker versions without migrations, if they don't exist, and adds the
+// foreign keys.
+func createBaseTables() error {
+	err := db.MigrateTable(model.TableServiceInstance, &struct {
+		ID              string `gorm:"primary_key;type:varchar(100)"`
+		ApplicationID   string `gorm:"type:varchar(100);not null;unique;column:application_id"`
+		ApplicationName string `gorm:"type:varchar(100);not null"`
+		SpaceID         string `gorm:"type:varchar(100);not null"`
+		OrgID           string `gorm:"type:varchar(100);not null"`
+		ConsumerKey     string `gorm:"type:varchar(100);not null"`
+		ConsumerSecret  string `gorm:"type:varchar(100);not null"`
+		ParameterHash   string `gorm:"type:varchar(100);not null"`
+	}{})
+	if err != nil {
+		return err
+	}
+	err = db.MigrateTable(model.TableSubscriptions, &struct {
+		ID            string `gorm:"primary_key;type:varchar(100);not null;unique"`
+		ApplicationID string `gorm:"type:varchar(100);not null"`
+		APIName       string `gorm:"type:varchar(100);not null"`
+		APIVersion    string `gorm:"type:varchar(100);not null"`
+		User          string `gorm:"type:varchar(100);not null"`
+		SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id"`
+	}{})
+	if err != nil {
+		return err
+	}
+	err = db.MigrateTable(model.TableBind, &struct {
+		ID            string `gorm:"primary_key;type:varchar(100)"`
+		SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id"`
+		PlatformAppID string `gorm:"type:varchar(100)"`
+	}{})
+	if err != nil {
+		return err
+	}
+	return addForeignKeys()
+}
+
+// addForeignKeys configures foreign keys for Subscription and Bind tables.
+// Returns any error encountered.
+func addForeignKeys() error {
+	// With this foreign key mapping all the subscriptions are deleted respective once the service instance is deleted.
+	err := db.AddForeignKey(&model.Subscription{}, model.ServiceInstanceIDFieldName, model.ForeignKeyDestSVCInstanceID, "CASCADE",
+		"CASCADE")
+	if err != nil {
+		return errors.Wrap(err, ErrMsgUnableToAddForeignKeys)
+	}
+	// With this foreign key mapping  it is restricted to delete a bind of a existing service instance.
+	err = db.AddForeignKey(&model.Bind{}, model.ServiceInstanceIDFieldName, model.ForeignKeyDestSVCInstanceID, "RESTRICT",
+		"RESTRICT")
+	if err != nil {
+		return errors.Wrap(err, ErrMsgUnableToAddForeignKeys)
+	}
+	// Unlike MySQL, PostgreSQL and SQLite don't index the referencing columns of a foreign key, which are needed to
+	// cascade and restrict the service instance deletes.
+	if db.Dialect() != db.MySQL {
+		for _, e := range []model.Entity{&model.Subscription{}, &model.Bind{}} {
+			err = db.AddIndex(e, "idx_"+e.TableName()+"_"+model.ServiceInstanceIDFieldName, model.ServiceInstanceIDFieldName)
+			if err != nil {
+				return errors.Wrap(err, ErrMsgUnableToAddForeignKeys)
+			}
+		}
+	}
+	return nil
+}
+
+func createOperationTable() error {
+	return db.MigrateTable(model.TableOperations, &struct {
+		ID            string `gorm:"primary_key;type:varchar(100)"`
+		SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id;index"`
+		Type          string `gorm:"type:varchar(20);not null"`
+		State         string `gorm:"type:varchar(20);not null"`
+		Description   string `gorm:"type:varchar(1000)"`
+		CreatedAt     time.Time
+		UpdatedAt     time.Time
+	}{})
+}
+
+// addInstancePlan adds the service and plan IDs of the service instances. The existing instances are instances of
+// the default Application plan.
+func addInstancePlan() error {
+	err := db.AddColumns(model.TableServiceInstance, &struct {
+		ServiceID string `gorm:"type:varchar(100);not null;default:''"`
+		PlanID    string `gorm:"type:varchar(100);not null;default:''"`
+	}{})
+	if err != nil {
+		return err
+	}
+	_, err = db.UpdateByQuery(&model.ServiceInstance{}, map[string]interface{}{"service_id": broker.ServiceID},
+		"service_id = ''")
+	if err != nil {
+		return errors.Wrap(err, "unable to set the service of the existing service instances")
+	}
+	_, err = db.UpdateByQuery(&model.ServiceInstance{}, map[string]interface{}{"plan_id": broker.ApplicationPlanID},
+		"plan_id = ''")
+	if err != nil {
+		return errors.Wrap(err, "unable to set the plan of the existing service instances")
+	}
+	return nil
+}
+
+func addBindParameters() error {
+	return db.AddColumns(model.TableBind, &struct {
+		Parameters string `gorm:"type:text"`
+	}{})
+}
+
+func addBindApplications() error {
+	return db.AddColumns(model.TableBind, &struct {
+		CredentialMode  string `gorm:"type:varchar(20)"`
+		ApplicationID   string `gorm:"type:varchar(100);column:application_id"`
+		ApplicationName string `gorm:"type:varchar(100)"`
+		ConsumerKey     string `gorm:"type:varchar(100)"`
+		ConsumerSecret  string `gorm:"type:varchar(100)"`
+	}{})
+}
+
+// addInstanceThrottlingPolicy adds the throttling policy of the service instances. The existing instances of the
+// default Application plan use its policy.
+func addInstanceThrottlingPolicy() error {
+	err := db.AddColumns(model.TableServiceInstance, &struct {
+		ThrottlingPolicy string `gorm:"type:varchar(100)"`
+	}{})
+	if err != nil {
+		return err
+	}
+	_, err = db.UpdateByQuery(&model.ServiceInstance{},
+		map[string]interface{}{"throttling_policy": broker.DefaultApplicationThrottlingPolicy},
+		"(throttling_policy IS NULL OR throttling_policy = '') AND plan_id = ?", broker.ApplicationPlanID)
+	if err != nil {
+		return errors.Wrap(err, "unable to set the throttling policy of the existing service instances")
+	}
+	return nil
+}
+
+func addSubscriptionTier() error {
+	return db.AddColumns(model.TableSubscriptions, &struct {
+		Tier string `gorm:"type:varchar(100)"`
+	}{})
+}
+
+func addSubscriptionAPIID() error {
+	return db.AddColumns(model.TableSubscriptions, &struct {
+		APIID string `gorm:"type:varchar(100);column:api_id"`
+	}{})
+}
+
+func addSubscriptionSelector() error {
+	return db.AddColumns(model.TableSubscriptions, &struct {
+		VersionSelector string `gorm:"type:varchar(100)"`
+		Follow          bool
+	}{})
+}
+
+func addInstancePlatform() error {
+	return db.AddColumns(model.TableServiceInstance, &struct {
+		Platform        string `gorm:"type:varchar(50)"`
+		PlatformContext string `gorm:"type:text"`
+	}{})
+}
+
+func createAPIInstanceTable() error {
+	return db.MigrateTable(model.TableAPIInstances, &struct {
+		ID              string `gorm:"primary_key;type:varchar(100)"`
+		ServiceID       string `gorm:"type:varchar(100);not null"`
+		PlanID          string `gorm:"type:varchar(100);not null"`
+		APIID           string `gorm:"type:varchar(100);not null;unique;column:api_id"`
+		APIName         string `gorm:"type:varchar(100);not null"`
+		APIVersion      string `gorm:"type:varchar(100);not null"`
+		LifecycleStatus string `gorm:"type:varchar(50)"`
+		Parameters      string `gorm:"type:text"`
+		ParameterHash   string `gorm:"type:varchar(100);not null"`
+		Platform        string `gorm:"type:varchar(50)"`
+		PlatformContext string `gorm:"type:text"`
+	}{})
+}
+
+func createSubscriptionInstanceTable() error {
+	return db.MigrateTable(model.TableSubscriptionInstances, &struct {
+		ID              string `gorm:"primary_key;type:varchar(100)"`
+		ServiceID       string `gorm:"type:varchar(100);not null"`
+		PlanID          string `gorm:"type:varchar(100);not null"`
+		SubscriptionID  string `gorm:"type:varchar(100);not null;unique"`
+		ApplicationID   string `gorm:"type:varchar(100);not null"`
+		ApplicationName string `gorm:"type:varchar(100);not null"`
+		APIID           string `gorm:"type:varchar(100);not null;column:api_id"`
+		APIName         string `gorm:"type:varchar(100);not null"`
+		APIVersion      string `gorm:"type:varchar(100);not null"`
+		Tier            string `gorm:"type:varchar(100)"`
+		Parameters      string `gorm:"type:text"`
+		ParameterHash   string `gorm:"type:varchar(100);not null"`
+		Platform        string `gorm:"type:varchar(50)"`
+		PlatformContext string `gorm:"type:text"`
+	}{})
+}
+
+func addInstanceAdoption() error {
+	return db.AddColumns(model.TableServiceInstance, &struct {
+		Adopted         bool
+		KeepApplication bool
+	}{})
+}
+
+func addInstanceDrift() error {
+	return db.AddColumns(model.TableServiceInstance, &struct {
+		Drift string `gorm:"type:text"`
+	}{})
+}
+
+func createSagaTables() error {
+	err := db.MigrateTable(model.TableSagas, &struct {
+		ID            string `gorm:"primary_key;type:varchar(100)"`
+		SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id;index"`
+		Type          string `gorm:"type:varchar(20);not null"`
+		CreatedAt     time.Time
+	}{})
+	if err != nil {
+		return err
+	}
+	return db.MigrateTable(model.TableSagaSteps, &struct {
+		ID              string `gorm:"primary_key;type:varchar(100)"`
+		SagaID          string `gorm:"type:varchar(100);not null;index"`
+		Seq             int    `gorm:"not null"`
+		Action          string `gorm:"type:varchar(50);not null"`
+		State           string `gorm:"type:varchar(20);not null"`
+		ResourceID      string `gorm:"type:varchar(100)"`
+		ResourceName    string `gorm:"type:varchar(100)"`
+		ResourceVersion string `gorm:"type:varchar(100)"`
+		ApplicationID   string `gorm:"type:varchar(100);column:application_id"`
+		APIID           string `gorm:"type:varchar(100);column:api_id"`
+	}{})
+}
+
+func createInstanceLockTable() error {
+	return db.MigrateTable(model.TableInstanceLocks, &struct {
+		ID        string    `gorm:"primary_key;type:varchar(100)"`
+		Owner     string    `gorm:"type:varchar(100);not null"`
+		Operation string    `gorm:"type:varchar(20);not null"`
+		ExpiresAt time.Time `gorm:"not null"`
+	}{})
+}
+
+func addInstanceRotation() error {
+	return db.AddColumns(model.TableServiceInstance, &struct {
+		SecretRotatedAt *time.Time
+	}{})
+}
+
+func addKeyParameters() error {
+	err := db.AddColumns(model.TableServiceInstance, &struct {
+		KeyParameters string `gorm:"type:text"`
+	}{})
+	if err != nil {
+		return err
+	}
+	return db.AddColumns(model.TableBind, &struct {
+		KeyParameters string `gorm:"type:text"`
+	}{})
+}
+
+func addBindCredentialsVersion() error {
+	return db.AddColumns(model.TableBind, &struct {
+		CredentialsVersion int
+	}{})
+}
+
+func addBindCredentialTemplate() error {
+	return db.AddColumns(model.TableBind, &struct {
+		CredentialTemplate string `gorm:"type:varchar(100)"`
+	}{})
+}
+
+func addOperationRequestHash() error {
+	return db.AddColumns(model.TableOperations, &struct {
+		RequestHash string `gorm:"type:varchar(100)"`
+	}{})
+}
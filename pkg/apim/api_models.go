@@ -149,6 +149,10 @@ type ApplicationCreateReq struct {
 // APIParam represents the structure for API plan parameters.
 type APIParam struct {
 	APISpec APIReqBody `json:"api"`
+	// Publish the API once it is created
+	Publish bool `json:"publish,omitempty"`
+	// Lifecycle state the API is moved to, e.g. PUBLISHED, DEPRECATED or RETIRED
+	Lifecycle string `json:"lifecycle,omitempty"`
 }
 
 // ApplicationParam represents the structure for Application plan parameters.
@@ -158,7 +162,9 @@ type ApplicationParam struct {
 
 // SubscriptionSpec represents the parameters for a Subscription.
 type SubscriptionSpec struct {
-	APIName          string `json:"apiName"`
+	APIName string `json:"apiName"`
+	// The version of the API, required if more than one version of the API exists
+	APIVersion       string `json:"apiVersion,omitempty"`
 	AppName          string `json:"appName"`
 	SubscriptionTier string `json:"tier"`
 }
@@ -247,6 +253,12 @@ type SubscriptionResp struct {
 	ThrottlingPolicy string `json:"throttlingPolicy"`
 }
 
+// SubscriptionListResp represents the response of list Subscriptions API call.
+type SubscriptionListResp struct {
+	Count int                `json:"count"`
+	List  []SubscriptionResp `json:"list"`
+}
+
 // SubscriptionRespApiInfo represents the API info of response of create Subscription API call.
 type SubscriptionRespApiInfo struct {
 	Name string `json:"name"`
@@ -284,6 +296,76 @@ type ApplicationSearchInfo struct {
 	Status         string `json:"status"`
 }
 
+// ApplicationInfo represents the response of get Application API call.
+type ApplicationInfo struct {
+	ApplicationID    string               `json:"applicationId"`
+	Name             string               `json:"name"`
+	ThrottlingPolicy string               `json:"throttlingPolicy"`
+	Description      string               `json:"description"`
+	Keys             []ApplicationKeyResp `json:"keys"`
+}
+
+// ThrottlingPolicyInfo represents a throttling policy available in the store.
+type ThrottlingPolicyInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	PolicyLevel string `json:"policyLevel"`
+}
+
+// ThrottlingPolicyListResp represents the response of list throttling policies API call.
+type ThrottlingPolicyListResp struct {
+	Count int                    `json:"count"`
+	List  []ThrottlingPolicyInfo `json:"list"`
+}
+
+// StoreAPIInfo represents an API available in the store.
+type StoreAPIInfo struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Context         string `json:"context"`
+	Version         string `json:"version"`
+	Provider        string `json:"provider"`
+	LifeCycleStatus string `json:"lifeCycleStatus"`
+}
+
+// APITier represents a subscription tier offered by an API.
+type APITier struct {
+	TierName string `json:"tierName"`
+	TierPlan string `json:"tierPlan"`
+}
+
+// StoreAPI represents the response of get API API call of the store.
+type StoreAPI struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Version      string           `json:"version"`
+	Context      string           `json:"context"`
+	Provider     string           `json:"provider"`
+	Tiers        []APITier        `json:"tiers"`
+	EndpointURLs []APIEndpointURL `json:"endpointURLs"`
+}
+
+// APIEndpointURL represents the gateway URLs of an API in a gateway environment.
+type APIEndpointURL struct {
+	EnvironmentName string  `json:"environmentName"`
+	EnvironmentType string  `json:"environmentType"`
+	URLs            APIURLs `json:"URLs"`
+}
+
+// APIURLs represents the gateway URLs of an API for each transport.
+type APIURLs struct {
+	HTTP  string `json:"http,omitempty"`
+	HTTPS string `json:"https,omitempty"`
+}
+
+// StoreAPIListResp represents the response of list APIs API call of the store.
+type StoreAPIListResp struct {
+	Count int            `json:"count"`
+	List  []StoreAPIInfo `json:"list"`
+}
+
 // ApplicationSearchResp represents the response of search Application by name API call.
 type ApplicationSearchResp struct {
 	Previous string                  `json:"previous"`
@@ -293,7 +375,73 @@ type ApplicationSearchResp struct {
 }
 
 var AppPlanBindInputParameterSchemaRaw = `{
-  "$schema": "http://json-schema.org/draft-04/schema#"
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "type": "object",
+  "properties": {
+    "credentialMode": {
+      "type": "string",
+      "enum": [
+        "instance",
+        "binding"
+      ]
+    },
+    "credentialsVersion": {
+      "type": "integer",
+      "enum": [
+        1,
+        2
+      ]
+    },
+    "accessToken": {
+      "type": "boolean"
+    },
+    "credentialTemplate": {
+      "type": "string"
+    },
+    "keys": {
+      "type": "object",
+      "properties": {
+        "keyType": {
+          "type": "string",
+          "enum": [
+            "PRODUCTION",
+            "SANDBOX"
+          ]
+        },
+        "grantTypes": {
+          "type": "array",
+          "items": {
+            "type": "string",
+            "enum": [
+              "client_credentials",
+              "password",
+              "refresh_token",
+              "authorization_code",
+              "implicit",
+              "urn:ietf:params:oauth:grant-type:saml2-bearer",
+              "urn:ietf:params:oauth:grant-type:jwt-bearer",
+              "iwa:ntlm"
+            ]
+          }
+        },
+        "callbackUrl": {
+          "type": "string",
+          "format": "uri"
+        },
+        "scopes": {
+          "type": "array",
+          "items": {
+            "type": "string",
+            "minLength": 1
+          }
+        },
+        "validityTime": {
+          "type": "integer",
+          "minimum": 0
+        }
+      }
+    }
+  }
 }`
 
 var AppPlanInputParameterSchemaRaw = `{
@@ -306,22 +454,232 @@ var AppPlanInputParameterSchemaRaw = `{
         {
           "type": "object",
           "properties": {
+            "id": {
+              "type": "string"
+            },
+            "context": {
+              "type": "string"
+            },
             "name": {
               "type": "string"
             },
             "version": {
               "type": "string"
+            },
+            "provider": {
+              "type": "string"
+            },
+            "tier": {
+              "type": "string"
+            },
+            "follow": {
+              "type": "boolean"
             }
           },
+          "anyOf": [
+            {
+              "required": [
+                "id"
+              ]
+            },
+            {
+              "required": [
+                "context"
+              ]
+            },
+            {
+              "required": [
+                "name",
+                "version"
+              ]
+            }
+          ]
+        }
+      ]
+    },
+    "application": {
+      "type": "object",
+      "properties": {
+        "id": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "keep": {
+          "type": "boolean"
+        }
+      },
+      "anyOf": [
+        {
           "required": [
-            "name",
-            "version"
+            "id"
           ]
+        },
+        {
+          "required": [
+            "name"
+          ]
+        }
+      ]
+    },
+    "rotateCredentials": {
+      "type": "boolean"
+    },
+    "keys": {
+      "type": "object",
+      "properties": {
+        "keyType": {
+          "type": "string",
+          "enum": [
+            "PRODUCTION",
+            "SANDBOX"
+          ]
+        },
+        "grantTypes": {
+          "type": "array",
+          "items": {
+            "type": "string",
+            "enum": [
+              "client_credentials",
+              "password",
+              "refresh_token",
+              "authorization_code",
+              "implicit",
+              "urn:ietf:params:oauth:grant-type:saml2-bearer",
+              "urn:ietf:params:oauth:grant-type:jwt-bearer",
+              "iwa:ntlm"
+            ]
+          }
+        },
+        "callbackUrl": {
+          "type": "string",
+          "format": "uri"
+        },
+        "scopes": {
+          "type": "array",
+          "items": {
+            "type": "string",
+            "minLength": 1
+          }
+        },
+        "validityTime": {
+          "type": "integer",
+          "minimum": 0
+        }
+      }
+    }
+  },
+  "anyOf": [
+    {
+      "required": [
+        "apis"
+      ]
+    },
+    {
+      "required": [
+        "application"
+      ]
+    },
+    {
+      "required": [
+        "rotateCredentials"
+      ]
+    }
+  ]
+}`
+
+var APIPlanInputParameterSchemaRaw = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "type": "object",
+  "properties": {
+    "api": {
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "description": {
+          "type": "string"
+        },
+        "context": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        },
+        "apiDefinition": {
+          "type": "string"
+        },
+        "endpointConfig": {
+          "type": "string"
+        },
+        "tiers": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "transport": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "visibility": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "name",
+        "context",
+        "version",
+        "apiDefinition",
+        "endpointConfig"
+      ]
+    },
+    "publish": {
+      "type": "boolean"
+    },
+    "lifecycle": {
+      "type": "string",
+      "enum": [
+        "CREATED",
+        "PUBLISHED",
+        "DEPRECATED",
+        "RETIRED"
+      ]
+    }
+  }
+}`
+
+var SubscriptionPlanInputParameterSchemaRaw = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "type": "object",
+  "properties": {
+    "subs": {
+      "type": "object",
+      "properties": {
+        "apiName": {
+          "type": "string"
+        },
+        "apiVersion": {
+          "type": "string"
+        },
+        "appName": {
+          "type": "string"
+        },
+        "tier": {
+          "type": "string"
         }
+      },
+      "required": [
+        "apiName",
+        "appName"
       ]
     }
   },
   "required": [
-    "apis"
+    "subs"
   ]
 }`
@@ -24,6 +24,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -36,15 +37,37 @@ import (
 
 const (
 	CreateAPIContext                  = "create API"
+	UpdateAPIContext                  = "update API"
+	APILifecycleChangeContext         = "change API lifecycle"
+	LifecycleActionPublish            = "Publish"
+	LifecycleActionRepublish          = "Re-Publish"
+	LifecycleActionDemoteToCreated    = "Demote to Created"
+	LifecycleActionDeprecate          = "Deprecate"
+	LifecycleActionRetire             = "Retire"
+	APIStatusCreated                  = "CREATED"
+	APIStatusPublished                = "PUBLISHED"
+	APIStatusDeprecated               = "DEPRECATED"
+	APIStatusRetired                  = "RETIRED"
 	CreateApplicationContext          = "create application"
 	CreateMultipleSubscriptionContext = "create multiple subscriptions"
+	CreateSubscriptionContext         = "create subscription"
 	UpdateApplicationContext          = "update application"
 	GenerateKeyContext                = "Generate application keys"
+	RegenerateSecretContext           = "regenerate consumer secret"
 	UnSubscribeContext                = "unsubscribe api"
 	ApplicationDeleteContext          = "delete application"
 	APIDeleteContext                  = "delete API"
 	APISearchContext                  = "search API"
 	ApplicationSearchContext          = "search Application"
+	ApplicationGetContext             = "get Application"
+	ApplicationListContext            = "list Applications"
+	SubscriptionListContext           = "list subscriptions"
+	ThrottlingPolicyListContext       = "list throttling policies"
+	APIListContext                    = "list APIs"
+	APIGetContext                     = "get API"
+	UpdateSubscriptionContext         = "update subscription"
+	ErrMsgAPIIDEmpty                  = "API id is empty"
+	ListPageLimit                     = 100
 	ErrMsgAPPIDEmpty                  = "application id is empty"
 )
 
@@ -53,6 +76,8 @@ var (
 	storeApplicationEndpoint          string
 	storeSubscriptionEndpoint         string
 	storeMultipleSubscriptionEndpoint string
+	storeAppThrottlingPolicyEndpoint  string
+	storeAPIEndpoint                  string
 	applicationDashBoardURLBase       string
 	tokenManager                      token.Manager
 	once                              sync.Once
@@ -66,6 +91,8 @@ func Init(manager token.Manager, conf config.APIM) {
 		storeApplicationEndpoint = createEndpoint(conf.StoreEndpoint, conf.StoreApplicationContext)
 		storeSubscriptionEndpoint = createEndpoint(conf.StoreEndpoint, conf.StoreSubscriptionContext)
 		storeMultipleSubscriptionEndpoint = createEndpoint(conf.StoreEndpoint, conf.StoreMultipleSubscriptionContext)
+		storeAppThrottlingPolicyEndpoint = createEndpoint(conf.StoreEndpoint, conf.StoreThrottlingPolicyContext)
+		storeAPIEndpoint = createEndpoint(conf.StoreEndpoint, conf.StoreAPIContext)
 		applicationDashBoardURLBase = createEndpoint(conf.StoreEndpoint, "/devportal/applications/")
 	})
 }
@@ -84,6 +111,9 @@ func createEndpoint(paths ...string) string {
 // Returns the API ID and any error encountered.
 func CreateAPI(reqBody *APIReqBody) (string, error) {
 	req, err := creatHTTPPOSTAPIRequest(publisherAPIEndpoint, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var resBody APICreateResp
 	err = send(CreateAPIContext, req, &resBody, http.StatusCreated)
 	if err != nil {
@@ -92,6 +122,38 @@ func CreateAPI(reqBody *APIReqBody) (string, error) {
 	return resBody.ID, nil
 }
 
+// UpdateAPI updates the given API with the provided API spec.
+// Returns any error encountered.
+func UpdateAPI(apiID string, reqBody *APIReqBody) error {
+	endpoint, err := utils.ConstructURL(publisherAPIEndpoint, apiID)
+	if err != nil {
+		return err
+	}
+	req, err := creatHTTPPUTAPIRequest(endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	return send(UpdateAPIContext, req, nil, http.StatusOK)
+}
+
+// ChangeAPILifecycle applies the given lifecycle action, e.g. "Publish", to the given API.
+// Returns any error encountered.
+func ChangeAPILifecycle(apiID, action string) error {
+	endpoint, err := utils.ConstructURL(publisherAPIEndpoint, "change-lifecycle")
+	if err != nil {
+		return err
+	}
+	req, err := creatHTTPPOSTAPIRequest(endpoint, nil)
+	if err != nil {
+		return err
+	}
+	q := url.Values{}
+	q.Add("apiId", apiID)
+	q.Add("action", action)
+	req.HTTPRequest().URL.RawQuery = q.Encode()
+	return send(APILifecycleChangeContext, req, nil, http.StatusOK)
+}
+
 // GetAppDashboardURL returns DashBoard URL for the given Application.
 func GetAppDashboardURL(appID string) string {
 	return applicationDashBoardURLBase + "/" + appID + "/overview"
@@ -130,13 +192,16 @@ func UpdateApplication(id string, reqBody *ApplicationCreateReq) error {
 	return nil
 }
 
-// GenerateKeys generates keys for the given application.
+// GenerateKeys generates keys for the given application with the given request. The default request is used if the
+// given request is nil.
 // Returns generated keys and any error encountered.
-func GenerateKeys(appID string) (*ApplicationKeyResp, error) {
+func GenerateKeys(appID string, reqBody *ApplicationKeyGenerateRequest) (*ApplicationKeyResp, error) {
 	if appID == "" {
 		return nil, errors.New(ErrMsgAPPIDEmpty)
 	}
-	reqBody := defaultApplicationKeyGenerateReq()
+	if reqBody == nil {
+		reqBody = DefaultApplicationKeyGenerateReq()
+	}
 	generateApplicationKeyEndpoint, err := utils.ConstructURL(storeApplicationEndpoint, appID, "/generate-keys")
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot construct endpoint")
@@ -153,6 +218,29 @@ func GenerateKeys(appID string) (*ApplicationKeyResp, error) {
 	return &resBody, nil
 }
 
+// RegenerateConsumerSecret regenerates the consumer secret of the keys of the given type of the given application.
+// The previous consumer secret is revoked by API-M.
+// Returns the consumer key and the new consumer secret and any error encountered.
+func RegenerateConsumerSecret(appID, keyType string) (*ApplicationKeyResp, error) {
+	if appID == "" {
+		return nil, errors.New(ErrMsgAPPIDEmpty)
+	}
+	endpoint, err := utils.ConstructURL(storeApplicationEndpoint, appID, "keys", keyType, "regenerate-secret")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot construct endpoint")
+	}
+	req, err := creatHTTPPOSTAPIRequest(endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	var resBody ApplicationKeyResp
+	err = send(RegenerateSecretContext, req, &resBody, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &resBody, nil
+}
+
 // CreateMultipleSubscriptions creates the given subscriptions.
 // Returns list of SubscriptionResp and any error encountered.
 func CreateMultipleSubscriptions(subs []SubscriptionReq) ([]SubscriptionResp, error) {
@@ -168,6 +256,35 @@ func CreateMultipleSubscriptions(subs []SubscriptionReq) ([]SubscriptionResp, er
 	return resBody, nil
 }
 
+// CreateSubscription creates the given subscription.
+// Returns the created subscription and any error encountered.
+func CreateSubscription(reqBody *SubscriptionReq) (*SubscriptionResp, error) {
+	req, err := creatHTTPPOSTAPIRequest(storeSubscriptionEndpoint, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	var resBody SubscriptionResp
+	err = send(CreateSubscriptionContext, req, &resBody, http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+	return &resBody, nil
+}
+
+// UpdateSubscription updates the given subscription with the provided subscription spec.
+// Returns any error encountered.
+func UpdateSubscription(subscriptionID string, reqBody *SubscriptionReq) error {
+	endpoint, err := utils.ConstructURL(storeSubscriptionEndpoint, subscriptionID)
+	if err != nil {
+		return err
+	}
+	req, err := creatHTTPPUTAPIRequest(endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	return send(UpdateSubscriptionContext, req, nil, http.StatusOK)
+}
+
 // UnSubscribe method removes the given subscription.
 // Returns any error encountered.
 func UnSubscribe(subscriptionID string) error {
@@ -300,17 +417,121 @@ func creatHTTPPUTAPIRequest(endpoint string, reqBody interface{}) (*client.HTTPR
 	return req, err
 }
 
+// creatHTTPGETAPIRequest returns a GET request with the given query parameters and any error encountered.
+func creatHTTPGETAPIRequest(endpoint string, params url.Values) (*client.HTTPRequest, error) {
+	aT, err := tokenManager.Token()
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.CreateHTTPGETRequest(aT, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req.HTTPRequest().URL.RawQuery = params.Encode()
+	return req, err
+}
+
+// ListSubscriptions returns the subscriptions of the given Application and any error encountered.
+func ListSubscriptions(appID string) ([]SubscriptionResp, error) {
+	if appID == "" {
+		return nil, errors.New(ErrMsgAPPIDEmpty)
+	}
+	var subs []SubscriptionResp
+	for offset := 0; ; offset += ListPageLimit {
+		q := pageQuery(offset)
+		q.Add("applicationId", appID)
+		req, err := creatHTTPGETAPIRequest(storeSubscriptionEndpoint, q)
+		if err != nil {
+			return nil, err
+		}
+		var resp SubscriptionListResp
+		err = send(SubscriptionListContext, req, &resp, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, resp.List...)
+		if len(resp.List) < ListPageLimit {
+			return subs, nil
+		}
+	}
+}
+
+// ListApplicationThrottlingPolicies returns the Application throttling policies available in the store and any error
+// encountered.
+func ListApplicationThrottlingPolicies() ([]ThrottlingPolicyInfo, error) {
+	var policies []ThrottlingPolicyInfo
+	for offset := 0; ; offset += ListPageLimit {
+		req, err := creatHTTPGETAPIRequest(storeAppThrottlingPolicyEndpoint, pageQuery(offset))
+		if err != nil {
+			return nil, err
+		}
+		var resp ThrottlingPolicyListResp
+		err = send(ThrottlingPolicyListContext, req, &resp, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, resp.List...)
+		if len(resp.List) < ListPageLimit {
+			return policies, nil
+		}
+	}
+}
+
+// ListAPIs returns the APIs available in the store and any error encountered.
+func ListAPIs() ([]StoreAPIInfo, error) {
+	var apis []StoreAPIInfo
+	for offset := 0; ; offset += ListPageLimit {
+		req, err := creatHTTPGETAPIRequest(storeAPIEndpoint, pageQuery(offset))
+		if err != nil {
+			return nil, err
+		}
+		var resp StoreAPIListResp
+		err = send(APIListContext, req, &resp, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		apis = append(apis, resp.List...)
+		if len(resp.List) < ListPageLimit {
+			return apis, nil
+		}
+	}
+}
+
+// GetStoreAPI returns the store view of the given API, which includes the subscription tiers of the API, and any
+// error encountered.
+func GetStoreAPI(apiID string) (*StoreAPI, error) {
+	if apiID == "" {
+		return nil, errors.New(ErrMsgAPIIDEmpty)
+	}
+	endpoint, err := utils.ConstructURL(storeAPIEndpoint, apiID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := creatHTTPGETAPIRequest(endpoint, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	var resp StoreAPI
+	err = send(APIGetContext, req, &resp, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// pageQuery returns the query parameters to retrieve the page of a list starting from the given offset.
+func pageQuery(offset int) url.Values {
+	q := url.Values{}
+	q.Add("limit", strconv.Itoa(ListPageLimit))
+	q.Add("offset", strconv.Itoa(offset))
+	return q
+}
+
 // SearchAPIByNameVersion method returns API ID of the Given API.
 // An error is returned if the number of result for the search is not equal to 1.
 // Returns API ID and any error encountered.
 func SearchAPIByNameVersion(apiName, version string) (string, error) {
-	query := "name:" + apiName + " version:" + version
-	req, err := creatAPIMSearchHTTPRequest(publisherAPIEndpoint, query)
-	if err != nil {
-		return "", err
-	}
-	var resp APISearchResp
-	err = send(APISearchContext, req, &resp, http.StatusOK)
+	resp, err := searchAPIs("name:" + apiName + " version:" + version)
 	if err != nil {
 		return "", err
 	}
@@ -323,6 +544,109 @@ func SearchAPIByNameVersion(apiName, version string) (string, error) {
 	return resp.List[0].ID, nil
 }
 
+// SearchAPI method returns the API matching the given search query, e.g. "context:/pizzashack version:1.0.0".
+// An error is returned if the number of result for the search is not equal to 1.
+// Returns the API information and any error encountered.
+func SearchAPI(query string) (*APISearchInfo, error) {
+	resp, err := searchAPIs(query)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count == 0 {
+		return nil, errors.New(fmt.Sprintf("couldn't find an API for the query %s", query))
+	}
+	if resp.Count > 1 {
+		return nil, errors.New(fmt.Sprintf("returned more than one API for the query %s", query))
+	}
+	return &resp.List[0], nil
+}
+
+// SearchAPIs method returns the APIs matching the given search query and any error encountered.
+func SearchAPIs(query string) ([]APISearchInfo, error) {
+	resp, err := searchAPIs(query)
+	if err != nil {
+		return nil, err
+	}
+	return resp.List, nil
+}
+
+// searchAPIs returns the result of the given API search query and any error encountered.
+func searchAPIs(query string) (*APISearchResp, error) {
+	req, err := creatAPIMSearchHTTPRequest(publisherAPIEndpoint, query)
+	if err != nil {
+		return nil, err
+	}
+	var resp APISearchResp
+	err = send(APISearchContext, req, &resp, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListApplications returns the Applications of the broker user and any error encountered.
+func ListApplications() ([]ApplicationSearchInfo, error) {
+	var apps []ApplicationSearchInfo
+	for offset := 0; ; offset += ListPageLimit {
+		req, err := creatHTTPGETAPIRequest(storeApplicationEndpoint, pageQuery(offset))
+		if err != nil {
+			return nil, err
+		}
+		var resp ApplicationSearchResp
+		err = send(ApplicationListContext, req, &resp, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, resp.List...)
+		if len(resp.List) < ListPageLimit {
+			return apps, nil
+		}
+	}
+}
+
+// SearchApplications returns the Applications matching the given query and any error encountered.
+func SearchApplications(query string) ([]ApplicationSearchInfo, error) {
+	var apps []ApplicationSearchInfo
+	for offset := 0; ; offset += ListPageLimit {
+		q := pageQuery(offset)
+		q.Add("query", query)
+		req, err := creatHTTPGETAPIRequest(storeApplicationEndpoint, q)
+		if err != nil {
+			return nil, err
+		}
+		var resp ApplicationSearchResp
+		err = send(ApplicationSearchContext, req, &resp, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, resp.List...)
+		if len(resp.List) < ListPageLimit {
+			return apps, nil
+		}
+	}
+}
+
+// GetApplication returns the Application with the given ID, including its keys, and any error encountered.
+func GetApplication(appID string) (*ApplicationInfo, error) {
+	if appID == "" {
+		return nil, errors.New(ErrMsgAPPIDEmpty)
+	}
+	endpoint, err := utils.ConstructURL(storeApplicationEndpoint, appID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := creatHTTPGETAPIRequest(endpoint, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	var resBody ApplicationInfo
+	err = send(ApplicationGetContext, req, &resBody, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &resBody, nil
+}
+
 // SearchApplication method returns Application ID of the Given Application.
 // An error is returned if the number of result for the search is not equal to 1.
 // Returns Application ID and any error encountered.
@@ -345,7 +669,8 @@ func SearchApplication(appName string) (string, error) {
 	return resp.List[0].ApplicationID, nil
 }
 
-func defaultApplicationKeyGenerateReq() *ApplicationKeyGenerateRequest {
+// DefaultApplicationKeyGenerateReq returns the key generation request used when no key generation options are given.
+func DefaultApplicationKeyGenerateReq() *ApplicationKeyGenerateRequest {
 	return &ApplicationKeyGenerateRequest{
 		ValidityTime:            "3600",
 		KeyType:                 "PRODUCTION",
@@ -19,6 +19,7 @@
 package apim
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"testing"
@@ -34,6 +35,8 @@ const (
 	StoreSubscriptionContext      = "/api/am/store/v1/subscriptions"
 	MultipleSubscriptionContext   = StoreSubscriptionContext + "/multiple"
 	PublisherAPIContext           = "/api/am/publisher/v1/apis"
+	StoreThrottlingPolicyContext  = "/api/am/store/v1/throttling-policies/application"
+	StoreAPIContext               = "/api/am/store/v1/apis"
 	successTestCase               = "success test case"
 	failureTestCase               = "failure test case"
 	ErrMsgTestIncorrectResult     = "expected value: %v but then returned value: %v"
@@ -58,6 +61,8 @@ func init() {
 		StoreMultipleSubscriptionContext: MultipleSubscriptionContext,
 		PublisherAPIContext:              PublisherAPIContext,
 		PublisherEndpoint:                publisherTestEndpoint,
+		StoreThrottlingPolicyContext:     StoreThrottlingPolicyContext,
+		StoreAPIContext:                  StoreAPIContext,
 	})
 
 }
@@ -148,12 +153,13 @@ func testUpdateApplicationSuccessFunc() func(t *testing.T) {
 
 func TestGenerateKeys(t *testing.T) {
 	t.Run(successTestCase, testGenerateKeysSuccessFunc())
+	t.Run("success test case 2", testGenerateKeysWithRequestSuccessFunc())
 	t.Run(failureTestCase, testGenerateKeysFailFunc())
 }
 
 func testGenerateKeysFailFunc() func(t *testing.T) {
 	return func(t *testing.T) {
-		_, err := GenerateKeys("")
+		_, err := GenerateKeys("", nil)
 		if err.Error() != ErrMsgAPPIDEmpty {
 			t.Error("Expecting an error : " + ErrMsgAPPIDEmpty + " got: " + err.Error())
 		}
@@ -173,7 +179,7 @@ func testGenerateKeysSuccessFunc() func(t *testing.T) {
 		}
 		httpmock.RegisterResponder(http.MethodPost, StoreTestEndpoint+StoreApplicationContext+"/123/generate-keys", responder)
 
-		got, err := GenerateKeys("123")
+		got, err := GenerateKeys("123", nil)
 		if err != nil {
 			t.Error(err)
 		}
@@ -183,6 +189,73 @@ func testGenerateKeysSuccessFunc() func(t *testing.T) {
 	}
 }
 
+func testGenerateKeysWithRequestSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		var got ApplicationKeyGenerateRequest
+		httpmock.RegisterResponder(http.MethodPost, StoreTestEndpoint+StoreApplicationContext+"/123/generate-keys",
+			func(req *http.Request) (*http.Response, error) {
+				if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+					return nil, err
+				}
+				return httpmock.NewJsonResponse(http.StatusOK, &ApplicationKeyResp{KeyType: got.KeyType})
+			})
+
+		_, err := GenerateKeys("123", &ApplicationKeyGenerateRequest{
+			KeyType:      "SANDBOX",
+			ValidityTime: "600",
+			CallbackURL:  "https://example.com/callback",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		if got.KeyType != "SANDBOX" {
+			t.Errorf(ErrMsgTestIncorrectResult, "SANDBOX", got.KeyType)
+		}
+		if got.CallbackURL != "https://example.com/callback" {
+			t.Errorf(ErrMsgTestIncorrectResult, "https://example.com/callback", got.CallbackURL)
+		}
+	}
+}
+
+func TestRegenerateConsumerSecret(t *testing.T) {
+	t.Run(successTestCase, testRegenerateConsumerSecretSuccessFunc())
+	t.Run(failureTestCase, testRegenerateConsumerSecretFailFunc())
+}
+
+func testRegenerateConsumerSecretFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		_, err := RegenerateConsumerSecret("", "PRODUCTION")
+		if err.Error() != ErrMsgAPPIDEmpty {
+			t.Error("Expecting an error : " + ErrMsgAPPIDEmpty + " got: " + err.Error())
+		}
+	}
+}
+
+func testRegenerateConsumerSecretSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, &ApplicationKeyResp{
+			ConsumerKey:    "key",
+			ConsumerSecret: "secret",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodPost, StoreTestEndpoint+StoreApplicationContext+"/123/keys/PRODUCTION/regenerate-secret", responder)
+
+		got, err := RegenerateConsumerSecret("123", "PRODUCTION")
+		if err != nil {
+			t.Error(err)
+		}
+		if got.ConsumerSecret != "secret" {
+			t.Errorf(ErrMsgTestIncorrectResult, "secret", got.ConsumerSecret)
+		}
+	}
+}
+
 func TestCreateMultipleSubscription(t *testing.T) {
 	t.Run(successTestCase, testCreateMultipleSubscriptionSuccessFunc())
 	t.Run(failureTestCase, testCreateMultipleSubscriptionFailFunc())
@@ -510,3 +583,398 @@ func testSearchApplicationFail2Func() func(t *testing.T) {
 		}
 	}
 }
+
+func TestListSubscriptions(t *testing.T) {
+	t.Run(successTestCase, testListSubscriptionsSuccessFunc())
+	t.Run(failureTestCase, testListSubscriptionsFailFunc())
+}
+
+func testListSubscriptionsSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, &SubscriptionListResp{
+			Count: 1,
+			List: []SubscriptionResp{{
+				SubscriptionID: "abc",
+				ApplicationId:  "123",
+			}},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreSubscriptionContext+"?applicationId=123&limit=100&offset=0", responder)
+		subs, err := ListSubscriptions("123")
+		if err != nil {
+			t.Error(err)
+		}
+		if len(subs) != 1 || subs[0].SubscriptionID != "abc" {
+			t.Errorf(ErrMsgTestIncorrectResult, "abc", subs)
+		}
+	}
+}
+
+func testListSubscriptionsFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		_, err := ListSubscriptions("")
+		if err == nil {
+			t.Error("Expecting an error")
+		}
+		if err.Error() != ErrMsgAPPIDEmpty {
+			t.Error("Expecting the error '" + ErrMsgAPPIDEmpty + "' but got " + err.Error())
+		}
+	}
+}
+
+func TestListApplicationThrottlingPolicies(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder, err := httpmock.NewJsonResponder(http.StatusOK, &ThrottlingPolicyListResp{
+		Count: 1,
+		List: []ThrottlingPolicyInfo{{
+			Name:        "10PerMin",
+			PolicyLevel: "application",
+		}},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreThrottlingPolicyContext+"?limit=100&offset=0", responder)
+	policies, err := ListApplicationThrottlingPolicies()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(policies) != 1 || policies[0].Name != "10PerMin" {
+		t.Errorf(ErrMsgTestIncorrectResult, "10PerMin", policies)
+	}
+}
+
+func TestListAPIs(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder, err := httpmock.NewJsonResponder(http.StatusOK, &StoreAPIListResp{
+		Count: 1,
+		List: []StoreAPIInfo{{
+			ID:      "abc",
+			Name:    "PizzaShackAPI",
+			Version: "1.0.0",
+		}},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreAPIContext+"?limit=100&offset=0", responder)
+	apis, err := ListAPIs()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(apis) != 1 || apis[0].ID != "abc" {
+		t.Errorf(ErrMsgTestIncorrectResult, "abc", apis)
+	}
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder, err := httpmock.NewJsonResponder(http.StatusOK, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	httpmock.RegisterResponder(http.MethodPut, StoreTestEndpoint+StoreSubscriptionContext+"/abc", responder)
+	err = UpdateSubscription("abc", &SubscriptionReq{
+		ApiID:            "123",
+		ApplicationID:    "456",
+		ThrottlingPolicy: "Gold",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetStoreAPI(t *testing.T) {
+	t.Run(successTestCase, testGetStoreAPISuccessFunc())
+	t.Run(failureTestCase, testGetStoreAPIFailFunc())
+}
+
+func testGetStoreAPISuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, &StoreAPI{
+			ID:    "abc",
+			Tiers: []APITier{{TierName: "Gold"}},
+			EndpointURLs: []APIEndpointURL{{
+				EnvironmentName: "Production and Sandbox",
+				URLs:            APIURLs{HTTPS: "https://localhost:8243/pizzashack/1.0.0"},
+			}},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreAPIContext+"/abc", responder)
+		api, err := GetStoreAPI("abc")
+		if err != nil {
+			t.Error(err)
+		}
+		if len(api.Tiers) != 1 || api.Tiers[0].TierName != "Gold" {
+			t.Errorf(ErrMsgTestIncorrectResult, "Gold", api.Tiers)
+		}
+		if len(api.EndpointURLs) != 1 || api.EndpointURLs[0].URLs.HTTPS != "https://localhost:8243/pizzashack/1.0.0" {
+			t.Errorf(ErrMsgTestIncorrectResult, "https://localhost:8243/pizzashack/1.0.0", api.EndpointURLs)
+		}
+	}
+}
+
+func testGetStoreAPIFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		_, err := GetStoreAPI("")
+		if err == nil {
+			t.Error("Expecting an error")
+		}
+		if err.Error() != ErrMsgAPIIDEmpty {
+			t.Error("Expecting the error '" + ErrMsgAPIIDEmpty + "' but got " + err.Error())
+		}
+	}
+}
+
+func TestSearchAPI(t *testing.T) {
+	t.Run(successTestCase, testSearchAPISuccessFunc())
+	t.Run(failureTestCase, testSearchAPIFailFunc())
+}
+
+func testSearchAPISuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, &APISearchResp{
+			Count: 1,
+			List: []APISearchInfo{{
+				ID:      "111-111",
+				Context: "/test",
+			}},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodGet, publisherTestEndpoint+PublisherAPIContext+"?query=context%3A%2Ftest", responder)
+		api, err := SearchAPI("context:/test")
+		if err != nil {
+			t.Error(err)
+		}
+		if api.ID != "111-111" {
+			t.Errorf(ErrMsgTestIncorrectResult, "111-111", api.ID)
+		}
+	}
+}
+
+func testSearchAPIFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, &APISearchResp{
+			Count: 2,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodGet, publisherTestEndpoint+PublisherAPIContext+"?query=context%3A%2Ftest", responder)
+		_, err = SearchAPI("context:/test")
+		if err == nil {
+			t.Error("Expecting an error")
+		}
+	}
+}
+
+func TestUpdateAPI(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder, err := httpmock.NewJsonResponder(http.StatusOK, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	httpmock.RegisterResponder(http.MethodPut, publisherTestEndpoint+PublisherAPIContext+"/abc", responder)
+	err = UpdateAPI("abc", &APIReqBody{
+		Name:    "Test",
+		Context: "/test",
+		Version: "v1",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChangeAPILifecycle(t *testing.T) {
+	t.Run(successTestCase, testChangeAPILifecycleSuccessFunc())
+	t.Run(failureTestCase, testChangeAPILifecycleFailFunc())
+}
+
+func testChangeAPILifecycleSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodPost, publisherTestEndpoint+PublisherAPIContext+"/change-lifecycle?action=Publish&apiId=abc", responder)
+		err = ChangeAPILifecycle("abc", LifecycleActionPublish)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func testChangeAPILifecycleFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusNotFound, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodPost, publisherTestEndpoint+PublisherAPIContext+"/change-lifecycle?action=Publish&apiId=abc", responder)
+		err = ChangeAPILifecycle("abc", LifecycleActionPublish)
+		if err == nil {
+			t.Error("Expecting an error with code: " + strconv.Itoa(http.StatusNotFound))
+		}
+	}
+}
+
+func TestCreateSubscription(t *testing.T) {
+	t.Run(successTestCase, testCreateSubscriptionSuccessFunc())
+	t.Run(failureTestCase, testCreateSubscriptionFailFunc())
+}
+
+func testCreateSubscriptionSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusCreated, &SubscriptionResp{
+			SubscriptionID: "abc",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodPost, StoreTestEndpoint+StoreSubscriptionContext, responder)
+		sub, err := CreateSubscription(&SubscriptionReq{
+			ApiID:            "123",
+			ApplicationID:    "456",
+			ThrottlingPolicy: "Gold",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		if sub.SubscriptionID != "abc" {
+			t.Errorf(ErrMsgTestIncorrectResult, "abc", sub.SubscriptionID)
+		}
+	}
+}
+
+func testCreateSubscriptionFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusNotFound, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodPost, StoreTestEndpoint+StoreSubscriptionContext, responder)
+		_, err = CreateSubscription(&SubscriptionReq{
+			ApiID:            "123",
+			ApplicationID:    "456",
+			ThrottlingPolicy: "Gold",
+		})
+		if err == nil {
+			t.Error("Expecting an error with code: " + strconv.Itoa(http.StatusNotFound))
+		}
+	}
+}
+
+func TestGetApplication(t *testing.T) {
+	t.Run(successTestCase, testGetApplicationSuccessFunc())
+	t.Run(failureTestCase, testGetApplicationFailFunc())
+}
+
+func testGetApplicationSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusOK, &ApplicationInfo{
+			ApplicationID: "abc",
+			Name:          "OrdersApp",
+			Keys: []ApplicationKeyResp{{
+				ConsumerKey: "key",
+				KeyType:     "PRODUCTION",
+			}},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreApplicationContext+"/abc", responder)
+		app, err := GetApplication("abc")
+		if err != nil {
+			t.Error(err)
+		}
+		if app.Name != "OrdersApp" {
+			t.Errorf(ErrMsgTestIncorrectResult, "OrdersApp", app.Name)
+		}
+		if len(app.Keys) != 1 || app.Keys[0].ConsumerKey != "key" {
+			t.Error("Expecting the keys of the Application")
+		}
+	}
+}
+
+func testGetApplicationFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		_, err := GetApplication("")
+		if err == nil || err.Error() != ErrMsgAPPIDEmpty {
+			t.Errorf(ErrMsgTestIncorrectResult, ErrMsgAPPIDEmpty, err)
+		}
+	}
+}
+
+func TestSearchApplications(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder, err := httpmock.NewJsonResponder(http.StatusOK, &ApplicationSearchResp{
+		Count: 1,
+		List: []ApplicationSearchInfo{{
+			ApplicationID: "abc",
+			Name:          "ServiceBroker_123",
+		}},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreApplicationContext+"?limit=100&offset=0&query=ServiceBroker_", responder)
+	apps, err := SearchApplications("ServiceBroker_")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(apps) != 1 || apps[0].ApplicationID != "abc" {
+		t.Errorf(ErrMsgTestIncorrectResult, "abc", apps)
+	}
+}
+
+func TestListApplications(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder, err := httpmock.NewJsonResponder(http.StatusOK, &ApplicationSearchResp{
+		Count: 1,
+		List: []ApplicationSearchInfo{{
+			ApplicationID: "abc",
+			Name:          "ServiceBroker_123",
+		}},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	httpmock.RegisterResponder(http.MethodGet, StoreTestEndpoint+StoreApplicationContext+"?limit=100&offset=0", responder)
+	apps, err := ListApplications()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(apps) != 1 || apps[0].ApplicationID != "abc" {
+		t.Errorf(ErrMsgTestIncorrectResult, "abc", apps)
+	}
+}
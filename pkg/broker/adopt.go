@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+// getProvisionServiceParams returns the service parameters of a provision request. The APIs may be omitted if an
+// existing Application is adopted.
+func getProvisionServiceParams(rawParams json.RawMessage, logData *log.Data) (ServiceParams, error) {
+	svcParams, err := unmarshalServiceParams(rawParams)
+	if err != nil {
+		return svcParams, err
+	}
+	err = validateKeyParams(svcParams.Keys, logData)
+	if err != nil {
+		return svcParams, err
+	}
+	if svcParams.Application == nil {
+		return getServiceParamsIfExists(rawParams, logData)
+	}
+	if svcParams.Application.ID == "" && svcParams.Application.Name == "" {
+		log.Error("Application to adopt is not identifiable", nil, logData)
+		return svcParams, &mapBrokerError.ErrorInvalidApplicationReference{}
+	}
+	if len(svcParams.APIs) == 0 {
+		return svcParams, nil
+	}
+	return getServiceParamsIfExists(rawParams, logData)
+}
+
+// adoptApplication returns the metadata of the referenced existing Application. Keys are generated with the given
+// parameters if the Application doesn't have keys of the requested type.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func adoptApplication(appRef *ApplicationRef, keyParams *KeyParams, logData *log.Data) (*apim.ApplicationMetadata, error) {
+	appID := appRef.ID
+	if appID == "" {
+		logData.Add(LogKeyApplicationName, appRef.Name)
+		id, err := apim.SearchApplication(appRef.Name)
+		if err != nil {
+			log.Error("unable to find the Application", err, logData)
+			return nil, &mapBrokerError.ErrorUnableToFindApplication{
+				AppName: appRef.Name,
+			}
+		}
+		appID = id
+	}
+	logData.Add(LogKeyAppID, appID)
+	app, err := apim.GetApplication(appID)
+	if err != nil {
+		log.Error("unable to retrieve the Application", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToFindApplication{
+			AppName: appID,
+		}
+	}
+	logData.Add(LogKeyApplicationName, app.Name)
+
+	managed, err := isManagedApplication(appID, logData)
+	if err != nil {
+		return nil, err
+	}
+	if managed {
+		log.Error("Application is already managed by a service instance", nil, logData)
+		return nil, &mapBrokerError.ErrorApplicationAlreadyManaged{
+			AppName: app.Name,
+		}
+	}
+
+	keys := applicationKeys(app.Keys, keyTypeOf(keyParams))
+	if keys == nil {
+		log.Debug("Application doesn't have keys of type "+keyTypeOf(keyParams), logData)
+		keys, err = generateKeysForApplication(appID, keyParams, logData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &apim.ApplicationMetadata{
+		Name:         app.Name,
+		ID:           appID,
+		Keys:         keys,
+		DashboardURL: apim.GetAppDashboardURL(appID),
+	}, nil
+}
+
+// isManagedApplication returns true if the given Application belongs to a service instance or a bind.
+func isManagedApplication(appID string, logData *log.Data) (bool, error) {
+	exists, err := db.Retrieve(&model.ServiceInstance{ApplicationID: appID})
+	if err != nil {
+		log.Error("unable to retrieve the service instance from database", err, logData)
+		return false, &mapBrokerError.ErrorUnableToRetrieveServiceInstance{}
+	}
+	if exists {
+		return true, nil
+	}
+	exists, err = db.Retrieve(&model.Bind{ApplicationID: appID})
+	if err != nil {
+		log.Error("unable to retrieve the bind from database", err, logData)
+		return false, &mapBrokerError.ErrorUnableToRetrieveBind{}
+	}
+	return exists, nil
+}
+
+// applicationKeys returns the keys of the given type or nil if there are none.
+func applicationKeys(keys []apim.ApplicationKeyResp, keyType string) *apim.ApplicationKeyResp {
+	for i := range keys {
+		if keys[i].KeyType == keyType && keys[i].ConsumerKey != "" {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// importSubscriptions stores the existing subscriptions of the adopted Application of the given instance and returns
+// the requested APIs which are not subscribed yet.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func importSubscriptions(svcInstance *model.ServiceInstance, requestedAPIs []API, logData *log.Data) ([]API, error) {
+	subsResponses, err := apim.ListSubscriptions(svcInstance.ApplicationID)
+	if err != nil {
+		log.Error("unable to list subscriptions from API-M", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToRetrieveSubscriptionList{}
+	}
+	subscriptions := getSubscriptionList(svcInstance.ID, subsResponses)
+	if len(subscriptions) == 0 && len(requestedAPIs) == 0 {
+		log.Error("adopted Application has no subscriptions and no APIs are defined", nil, logData)
+		return nil, &mapBrokerError.ErrorEmptyAPIParameterSet{}
+	}
+	var existingAPIs []API
+	for _, sub := range subscriptions {
+		existingAPIs = append(existingAPIs, subscriptionAPI(sub))
+	}
+	if len(subscriptions) != 0 {
+		log.Debug("import the subscriptions of the Application", logData)
+		err = storeSubscriptions(subscriptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return getAddedAPIs(existingAPIs, requestedAPIs, logData), nil
+}
+
+// revertProvisionedApplication deletes the Application created for an instance. Adopted Applications are kept.
+func revertProvisionedApplication(appID string, appRef *ApplicationRef, logData *log.Data) {
+	if appRef != nil {
+		return
+	}
+	revertApplication(appID, logData)
+}
@@ -0,0 +1,455 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/pivotal-cf/brokerapi/domain"
+	"github.com/pivotal-cf/brokerapi/domain/apiresponses"
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/client"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+	"github.com/wso2/openservicebroker-apim/pkg/utils"
+)
+
+const (
+	APIPlanID          = "4b1d0e5c-7a3a-4c2b-9f55-3c1e8e1d2a6f"
+	APIPlanName        = "api"
+	APIPlanDescription = "Creates an API in WSO2 API Manager and optionally publishes it"
+	LogKeyAPIID        = "api-id"
+	LogKeyAPIName      = "api-name"
+)
+
+var (
+	apiPlanEnabled              bool
+	apiPlanBindable             = false
+	apiPlanInputParameterSchema map[string]interface{}
+)
+
+// initAPIPlan initializes the API plan if it is enabled in the catalog configuration.
+func initAPIPlan(enabled bool) {
+	apiPlanEnabled = enabled
+	if !enabled {
+		return
+	}
+	var err error
+	apiPlanInputParameterSchema, err = utils.GetJSONSchema(apim.APIPlanInputParameterSchemaRaw)
+	if err != nil {
+		log.HandleErrorAndExit(fmt.Sprintf(ErrMsgUnableToGenInputSchema, APIPlanName), err)
+	}
+}
+
+// isAPIPlan returns true if the API plan is enabled and the given plan ID is the ID of the API plan.
+func isAPIPlan(planID string) bool {
+	return apiPlanEnabled && planID == APIPlanID
+}
+
+// apiServicePlan returns the catalog entry of the API plan.
+func apiServicePlan() domain.ServicePlan {
+	return domain.ServicePlan{
+		ID:          APIPlanID,
+		Name:        APIPlanName,
+		Description: APIPlanDescription,
+		Bindable:    &apiPlanBindable,
+		Schemas: &domain.ServiceSchemas{
+			Instance: domain.ServiceInstanceSchema{
+				Create: domain.Schema{
+					Parameters: apiPlanInputParameterSchema,
+				},
+				Update: domain.Schema{
+					Parameters: apiPlanInputParameterSchema,
+				},
+			},
+		},
+	}
+}
+
+// getAPIPlanParams parses and validates the parameters of an API plan request.
+func getAPIPlanParams(rawParams json.RawMessage, logData *log.Data) (*apim.APIParam, error) {
+	params, err := parseAPIPlanParams(rawParams, logData)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAPISpec(&params.APISpec, logData)
+	if err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func parseAPIPlanParams(rawParams json.RawMessage, logData *log.Data) (*apim.APIParam, error) {
+	var params apim.APIParam
+	err := json.Unmarshal(rawParams, &params)
+	if err != nil {
+		log.Error("unable to parse the API plan parameters", err, logData)
+		return nil, &mapBrokerError.ErrorInvalidAPIPlanParameters{}
+	}
+	return &params, nil
+}
+
+func validateAPISpec(spec *apim.APIReqBody, logData *log.Data) error {
+	if spec.Name == "" || spec.Context == "" || spec.Version == "" || spec.APIDefinition == "" || spec.EndpointConfig == "" {
+		log.Error("required API attributes are missing", nil, logData)
+		return &mapBrokerError.ErrorInvalidAPIPlanParameters{}
+	}
+	logData.Add(LogKeyAPIName, spec.Name)
+	return nil
+}
+
+// isEmptyAPISpec returns true if no attribute of the API is given, i.e. the request only changes the lifecycle.
+func isEmptyAPISpec(spec *apim.APIReqBody) bool {
+	return spec.Name == "" && spec.Context == "" && spec.Version == "" && spec.APIDefinition == "" &&
+		spec.EndpointConfig == ""
+}
+
+func generateHashForAPIParams(params *apim.APIParam, logData *log.Data) (string, error) {
+	generatedHash, err := hashstructure.Hash(params, nil)
+	if err != nil {
+		log.Error("unable to generate hash value for API plan parameters", err, logData)
+		return "", &mapBrokerError.ErrorUnableToGenerateHash{}
+	}
+	return strconv.FormatUint(generatedHash, 10), nil
+}
+
+// provisionAPIPlan handles a provision request of the API plan.
+func provisionAPIPlan(svcInstanceID string, details *domain.ProvisionDetails, asyncAllowed bool, logData *log.Data) (domain.ProvisionedServiceSpec, error) {
+	platform, err := readPlatformContext(details, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	params, err := getAPIPlanParams(details.RawParameters, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	err = validateLifecycleTransition(apim.APIStatusCreated, requestedLifecycle(params, apim.APIStatusCreated), logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	paramHash, err := generateHashForAPIParams(params, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	requestHash, err := generateProvisionRequestHash(details.PlanID, paramHash, platform, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op != nil {
+		return provisionInProgress(op, requestHash, asyncAllowed)
+	}
+	lock, err := lockInstance(svcInstanceID, model.OperationProvision, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	defer lock.release()
+
+	apiInstance, err := retrieveAPIInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if apiInstance != nil {
+		if apiInstance.ParameterHash == paramHash && apiInstance.Platform == platform.platform &&
+			apiInstance.PlatformContext == platform.identifiersJSON() {
+			return domain.ProvisionedServiceSpec{
+				AlreadyExists: true,
+			}, nil
+		}
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+	exists, err := isExistingInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if exists {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+
+	apiInstance = &model.APIInstance{
+		ID:              svcInstanceID,
+		ServiceID:       details.ServiceID,
+		PlanID:          details.PlanID,
+		APIName:         params.APISpec.Name,
+		APIVersion:      params.APISpec.Version,
+		Parameters:      string(details.RawParameters),
+		ParameterHash:   paramHash,
+		Platform:        platform.platform,
+		PlatformContext: platform.identifiersJSON(),
+	}
+	if asyncAllowed {
+		op, err := startRequestOperation(svcInstanceID, model.OperationProvision, requestHash, func() error {
+			return createAPIInstance(apiInstance, params, logData)
+		}, logData)
+		if err != nil {
+			return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.ProvisionedServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err = createAPIInstance(apiInstance, params, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	return domain.ProvisionedServiceSpec{}, nil
+}
+
+// createAPIInstance creates the API in API-M, moves it to the requested lifecycle state and stores the given API
+// instance.
+// The API is deleted if any of the following steps fail. Returns any error encountered.
+func createAPIInstance(apiInstance *model.APIInstance, params *apim.APIParam, logData *log.Data) error {
+	s, err := beginSaga(apiInstance.ID, model.OperationProvision, logData)
+	if err != nil {
+		return err
+	}
+	defer s.end()
+	step, err := journalStep(apiInstance.ID, &model.SagaStep{
+		Action:          model.SagaStepCreateAPI,
+		ResourceName:    params.APISpec.Name,
+		ResourceVersion: params.APISpec.Version,
+	})
+	if err != nil {
+		return err
+	}
+	apiID, err := apim.CreateAPI(&params.APISpec)
+	if err != nil {
+		log.Error("unable to create the API", err, logData)
+		return handleAPIMResourceCreateError(err, params.APISpec.Name, logData)
+	}
+	completeStep(step, apiID, logData)
+	logData.Add(LogKeyAPIID, apiID)
+	apiInstance.APIID = apiID
+	apiInstance.LifecycleStatus = apim.APIStatusCreated
+	err = changeAPILifecycle(apiInstance, requestedLifecycle(params, apim.APIStatusCreated), logData)
+	if err != nil {
+		revertAPI(apiID, logData)
+		return err
+	}
+	err = db.Store(apiInstance)
+	if err != nil {
+		log.Error("unable to store the API instance", err, logData)
+		revertAPI(apiID, logData)
+		return &mapBrokerError.ErrorUnableToStoreAPIInstance{}
+	}
+	return nil
+}
+
+func revertAPI(apiID string, logData *log.Data) {
+	err := apim.DeleteAPI(apiID)
+	if err != nil {
+		log.Error("unable to delete the API", err, logData)
+	}
+}
+
+// updateAPIPlan handles an update request of an API plan instance. A request with only the "lifecycle" parameter
+// moves the API to the given lifecycle state without changing the API.
+func updateAPIPlan(apiInstance *model.APIInstance, details *domain.UpdateDetails, asyncAllowed bool, logData *log.Data) (domain.UpdateServiceSpec, error) {
+	if details.PlanID != "" && details.PlanID != apiInstance.PlanID {
+		log.Error("plan of an API instance cannot be changed", nil, logData)
+		return domain.UpdateServiceSpec{}, apiresponses.ErrPlanChangeNotSupported
+	}
+	params, err := parseAPIPlanParams(details.RawParameters, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	lifecycle := requestedLifecycle(params, apiInstance.LifecycleStatus)
+	err = validateLifecycleTransition(apiInstance.LifecycleStatus, lifecycle, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+
+	var update func() error
+	if isEmptyAPISpec(&params.APISpec) {
+		if lifecycle == "" {
+			log.Error("neither the API nor the lifecycle is given", nil, logData)
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(&mapBrokerError.ErrorInvalidAPIPlanParameters{})
+		}
+		if lifecycle == apiInstance.LifecycleStatus {
+			log.Debug("API is already in the requested lifecycle state", logData)
+			return domain.UpdateServiceSpec{}, nil
+		}
+		update = func() error {
+			return updateAPILifecycle(apiInstance, lifecycle, logData)
+		}
+	} else {
+		err = validateAPISpec(&params.APISpec, logData)
+		if err != nil {
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		paramHash, err := generateHashForAPIParams(params, logData)
+		if err != nil {
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		if paramHash == apiInstance.ParameterHash && (lifecycle == "" || lifecycle == apiInstance.LifecycleStatus) {
+			log.Debug("API parameters are not changed", logData)
+			return domain.UpdateServiceSpec{}, nil
+		}
+		apiInstance.Parameters = string(details.RawParameters)
+		apiInstance.ParameterHash = paramHash
+		update = func() error {
+			return updateAPIInstance(apiInstance, params, lifecycle, logData)
+		}
+	}
+
+	if asyncAllowed {
+		op, err := startOperation(apiInstance.ID, model.OperationUpdate, update, logData)
+		if err != nil {
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.UpdateServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err = update()
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	return domain.UpdateServiceSpec{}, nil
+}
+
+// updateAPIInstance updates the API in API-M, moves it to the given lifecycle state and updates the given API
+// instance. Returns any error encountered.
+func updateAPIInstance(apiInstance *model.APIInstance, params *apim.APIParam, lifecycle string, logData *log.Data) error {
+	params.APISpec.ID = apiInstance.APIID
+	params.APISpec.Status = apiInstance.LifecycleStatus
+	err := apim.UpdateAPI(apiInstance.APIID, &params.APISpec)
+	if err != nil {
+		log.Error("unable to update the API", err, logData)
+		return handleAPIMResourceUpdateError(err, apiInstance.APIName)
+	}
+	apiInstance.APIName = params.APISpec.Name
+	apiInstance.APIVersion = params.APISpec.Version
+	err = changeAPILifecycle(apiInstance, lifecycle, logData)
+	if err != nil {
+		return err
+	}
+	return updateAPIInstanceRecord(apiInstance, logData)
+}
+
+// updateAPILifecycle moves the API of the given API instance to the given lifecycle state and records the resulting
+// state. Returns any error encountered.
+func updateAPILifecycle(apiInstance *model.APIInstance, lifecycle string, logData *log.Data) error {
+	err := changeAPILifecycle(apiInstance, lifecycle, logData)
+	if err != nil {
+		return err
+	}
+	return updateAPIInstanceRecord(apiInstance, logData)
+}
+
+func updateAPIInstanceRecord(apiInstance *model.APIInstance, logData *log.Data) error {
+	err := db.Update(apiInstance)
+	if err != nil {
+		log.Error("unable to update the API instance", err, logData)
+		return &mapBrokerError.ErrorUnableToStoreAPIInstance{}
+	}
+	return nil
+}
+
+// deprovisionAPIPlan handles a deprovision request of an API plan instance.
+func deprovisionAPIPlan(apiInstance *model.APIInstance, asyncAllowed bool, logData *log.Data) (domain.DeprovisionServiceSpec, error) {
+	if asyncAllowed {
+		op, err := startOperation(apiInstance.ID, model.OperationDeprovision, func() error {
+			return deprovisionAPIInstance(apiInstance, logData)
+		}, logData)
+		if err != nil {
+			return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.DeprovisionServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err := deprovisionAPIInstance(apiInstance, logData)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	return domain.DeprovisionServiceSpec{}, nil
+}
+
+// deprovisionAPIInstance deletes the API in API-M and removes the given API instance from the database. An API which
+// is already deleted in API-M is ignored. Returns any error encountered.
+func deprovisionAPIInstance(apiInstance *model.APIInstance, logData *log.Data) error {
+	log.Debug("delete the API", logData)
+	err := apim.DeleteAPI(apiInstance.APIID)
+	if err != nil {
+		e, ok := err.(*client.InvokeError)
+		if !ok || e.StatusCode != http.StatusNotFound {
+			log.Error("unable to delete the API", err, logData)
+			return &mapBrokerError.ErrorUnableToDeleteAPIMResource{}
+		}
+		log.Debug("API doesn't exist in API-M", logData)
+	}
+	err = db.Delete(&model.APIInstance{ID: apiInstance.ID})
+	if err != nil {
+		log.Error("unable to delete the API instance from the database", err, logData)
+		return &mapBrokerError.ErrorUnableToDeleteInstance{}
+	}
+	return nil
+}
+
+// apiInstanceDetails returns the details of the given API plan instance. The "lifecycle" parameter reports the
+// current lifecycle state of the API.
+func apiInstanceDetails(apiInstance *model.APIInstance) domain.GetInstanceDetailsSpec {
+	params := map[string]interface{}{}
+	err := json.Unmarshal([]byte(apiInstance.Parameters), &params)
+	if err != nil {
+		params = map[string]interface{}{}
+	}
+	delete(params, "publish")
+	params["lifecycle"] = apiInstance.LifecycleStatus
+	return domain.GetInstanceDetailsSpec{
+		ServiceID:  apiInstance.ServiceID,
+		PlanID:     apiInstance.PlanID,
+		Parameters: params,
+	}
+}
+
+// retrieveAPIInstance returns the API plan instance with the given ID or nil if it doesn't exist.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func retrieveAPIInstance(svcInstanceID string, logData *log.Data) (*model.APIInstance, error) {
+	apiInstance := &model.APIInstance{
+		ID: svcInstanceID,
+	}
+	exists, err := db.Retrieve(apiInstance)
+	if err != nil {
+		log.Error("unable to retrieve the API instance from database", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToRetrieveAPIInstance{}
+	}
+	if !exists {
+		return nil, nil
+	}
+	logData.Add(LogKeyAPIID, apiInstance.APIID)
+	return apiInstance, nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+	"github.com/wso2/openservicebroker-apim/pkg/utils"
+)
+
+// isIdentifiableAPI returns true if the given API parameter is identified by an ID, a context or a name and version.
+func isIdentifiableAPI(api API) bool {
+	return api.ID != "" || api.Context != "" || (api.Name != "" && api.Version != "")
+}
+
+// apiSearchQuery returns the API-M search query which identifies the given API parameter.
+func apiSearchQuery(api API) string {
+	var query string
+	if api.Context != "" {
+		query = "context:" + api.Context
+	} else {
+		query = "name:" + api.Name
+	}
+	if api.Version != "" {
+		query += " version:" + api.Version
+	}
+	if api.Provider != "" {
+		query += " provider:" + api.Provider
+	}
+	return query
+}
+
+// resolveAPI returns the given API parameter with the ID, name, version, context and provider of the matching API in
+// API-M.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func resolveAPI(api API, logData *log.Data) (API, error) {
+	if api.ID == "" && utils.IsVersionSelector(api.Version) {
+		return resolveVersionSelector(api, logData)
+	}
+	if api.ID != "" {
+		storeAPI, err := apim.GetStoreAPI(api.ID)
+		if err != nil {
+			log.Error("unable to retrieve the API "+api.ID, err, logData)
+			return api, &mapBrokerError.ErrorUnableToSearchAPIs{}
+		}
+		api.Name = storeAPI.Name
+		api.Version = storeAPI.Version
+		api.Context = storeAPI.Context
+		api.Provider = storeAPI.Provider
+		return api, nil
+	}
+	query := apiSearchQuery(api)
+	apiInfo, err := apim.SearchAPI(query)
+	if err != nil {
+		log.Error("unable to search the API with the query "+query, err, logData)
+		return api, &mapBrokerError.ErrorUnableToSearchAPIs{}
+	}
+	api.ID = apiInfo.ID
+	api.Name = apiInfo.Name
+	api.Version = apiInfo.Version
+	api.Context = apiInfo.Context
+	api.Provider = apiInfo.Provider
+	return api, nil
+}
+
+// resolveAPIs resolves each of the given API parameters.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func resolveAPIs(apis []API, logData *log.Data) ([]API, error) {
+	var resolved []API
+	for _, api := range apis {
+		r, err := resolveAPI(api, logData)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
+// isSameAPI returns true if the given APIs refer to the same API in API-M. APIs are compared by ID if both are known,
+// otherwise by name, version and provider.
+func isSameAPI(a, b API) bool {
+	if a.ID != "" && b.ID != "" {
+		return a.ID == b.ID
+	}
+	if a.Provider != "" && b.Provider != "" && a.Provider != b.Provider {
+		return false
+	}
+	return a.Name == b.Name && a.Version == b.Version
+}
+
+// backfillSubscriptionAPIIDs resolves and stores the API IDs of the given subscriptions which are created before the
+// API IDs were stored. The API of such a subscription is searched by its name, version and provider, and a subscription
+// whose API can't be resolved is left without an ID.
+func backfillSubscriptionAPIIDs(subscriptions []model.Subscription, logData *log.Data) {
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		if sub.APIID != "" {
+			continue
+		}
+		api, err := resolveAPI(API{Name: sub.APIName, Version: sub.APIVersion, Provider: sub.User}, logData)
+		if err != nil {
+			continue
+		}
+		sub.APIID = api.ID
+		err = db.Update(sub)
+		if err != nil {
+			log.Error("unable to store the API ID of the subscription "+sub.ID, err, logData)
+		}
+	}
+}
+
+// subscriptionAPI returns the API of the given subscription.
+func subscriptionAPI(sub model.Subscription) API {
+	return API{
+		ID:              sub.APIID,
+		Name:            sub.APIName,
+		Version:         sub.APIVersion,
+		Provider:        sub.User,
+		Tier:            sub.Tier,
+		Follow:          sub.Follow,
+		VersionSelector: sub.VersionSelector,
+	}
+}
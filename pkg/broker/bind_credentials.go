@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/client"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	// CredentialModeInstance shares the keys of the service instance Application with all the binds.
+	CredentialModeInstance = "instance"
+	// CredentialModeBinding creates an Application with its own keys for each bind.
+	CredentialModeBinding = "binding"
+	LogKeyCredentialMode  = "credential-mode"
+)
+
+var defaultCredentialMode = CredentialModeInstance
+
+// BindParams represents the bind parameters.
+type BindParams struct {
+	CredentialMode string `json:"credentialMode"`
+	// Keys are the options of the keys of the bind Application. They are only accepted with the binding credential
+	// mode and default to the options of the service instance.
+	Keys *KeyParams `json:"keys,omitempty"`
+	// CredentialsVersion is the format of the credentials
+	CredentialsVersion int `json:"credentialsVersion,omitempty"`
+	// AccessToken adds a new access token to the credentials each time they are fetched. It requires the version 2
+	// credentials.
+	AccessToken bool `json:"accessToken,omitempty"`
+	// CredentialTemplate is the name of the configured template rendering the credentials. It defaults to the
+	// template of the plan.
+	CredentialTemplate string `json:"credentialTemplate,omitempty"`
+}
+
+// getBindParams returns the bind parameters with the defaults applied and any error encountered.
+func getBindParams(rawParams json.RawMessage, logData *log.Data) (BindParams, error) {
+	var params BindParams
+	if len(rawParams) != 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			log.Error("unable to parse the bind parameters", err, logData)
+			return params, &mapBrokerError.ErrorInvalidBindParameters{}
+		}
+	}
+	if params.CredentialMode == "" {
+		params.CredentialMode = defaultCredentialMode
+	}
+	if params.CredentialMode != CredentialModeInstance && params.CredentialMode != CredentialModeBinding {
+		log.Error("invalid credential mode: "+params.CredentialMode, nil, logData)
+		return params, &mapBrokerError.ErrorInvalidBindParameters{}
+	}
+	if params.Keys != nil && params.CredentialMode != CredentialModeBinding {
+		log.Error("keys are only accepted with the binding credential mode", nil, logData)
+		return params, &mapBrokerError.ErrorInvalidBindParameters{}
+	}
+	if params.CredentialsVersion == 0 {
+		params.CredentialsVersion = defaultCredentialsVersion
+	}
+	if !isValidCredentialsVersion(params.CredentialsVersion) {
+		log.Error(fmt.Sprintf("invalid credentials version: %d", params.CredentialsVersion), nil, logData)
+		return params, &mapBrokerError.ErrorInvalidBindParameters{}
+	}
+	if params.AccessToken && params.CredentialsVersion < CredentialsVersion2 {
+		log.Error("an access token requires the version 2 credentials", nil, logData)
+		return params, &mapBrokerError.ErrorInvalidBindParameters{}
+	}
+	if !isCredentialTemplate(params.CredentialTemplate) {
+		log.Error("unknown credential template: "+params.CredentialTemplate, nil, logData)
+		return params, &mapBrokerError.ErrorInvalidBindParameters{}
+	}
+	return params, validateKeyParams(params.Keys, logData)
+}
+
+// credentialModeOf returns the credential mode of the given Bind. Binds created before the credential modes were
+// introduced use the instance keys.
+func credentialModeOf(bind *model.Bind) string {
+	if bind.CredentialMode == "" {
+		return CredentialModeInstance
+	}
+	return bind.CredentialMode
+}
+
+// bindCredentialsMap returns the credentials of the given Bind.
+func bindCredentialsMap(bind *model.Bind, svcInstance *model.ServiceInstance) map[string]interface{} {
+	if credentialModeOf(bind) == CredentialModeBinding {
+		return credentialsMap(bind.ApplicationName, bind.ConsumerKey, bind.ConsumerSecret)
+	}
+	return credentialsMap(svcInstance.ApplicationName, svcInstance.ConsumerKey, svcInstance.ConsumerSecret)
+}
+
+// bindApplicationTarget returns a copy of the given instance pointing to the given Application so that the
+// subscription functions of the instance can be reused for the Bind Applications.
+func bindApplicationTarget(svcInstance *model.ServiceInstance, appID string) *model.ServiceInstance {
+	target := *svcInstance
+	target.ApplicationID = appID
+	return &target
+}
+
+// createBindApplication creates an Application dedicated to the given Bind, generates its keys with the given key
+// parameters, or the ones of the service instance if nil, and subscribes it to the APIs of the service instance. The
+// Application details and keys are set to the given Bind.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func createBindApplication(bind *model.Bind, svcInstance *model.ServiceInstance, keyParams *KeyParams, logData *log.Data) error {
+	apis, err := getExistingAPIsForAppID(svcInstance.ApplicationID, logData)
+	if err != nil {
+		return err
+	}
+	if keyParams == nil {
+		keyParams = unmarshalKeyParams(svcInstance.KeyParameters)
+	}
+	throttlingPolicy, err := instanceThrottlingPolicy(svcInstance, logData)
+	if err != nil {
+		return err
+	}
+	appMetadata, err := createApplicationAndGenerateKeys(bind.ID, throttlingPolicy, keyParams, logData)
+	if err != nil {
+		return err
+	}
+	bind.ApplicationID = appMetadata.ID
+	bind.ApplicationName = appMetadata.Name
+	err = createAndStoreSubscriptions(bindApplicationTarget(svcInstance, appMetadata.ID), apis, logData)
+	if err != nil {
+		revertBindApplication(bind, logData)
+		return err
+	}
+	bind.ConsumerKey = appMetadata.Keys.ConsumerKey
+	bind.ConsumerSecret = appMetadata.Keys.ConsumerSecret
+	bind.KeyParameters = marshalKeyParams(keyParams)
+	return nil
+}
+
+// deleteBindApplication deletes the Application of the given Bind, which revokes its keys and tokens, and removes
+// its subscriptions from the database.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func deleteBindApplication(bind *model.Bind, logData *log.Data) error {
+	logData.Add(LogKeyAppID, bind.ApplicationID).
+		Add(LogKeyApplicationName, bind.ApplicationName)
+	err := apim.DeleteApplication(bind.ApplicationID)
+	if err != nil {
+		invokeErr, ok := err.(*client.InvokeError)
+		if !ok || invokeErr.StatusCode != http.StatusNotFound {
+			log.Error("unable to delete the bind Application", err, logData)
+			return &mapBrokerError.ErrorUnableToDeleteAPIMResource{}
+		}
+		log.Debug("bind Application doesn't exist in API-M", logData)
+	}
+	subscriptions, err := getSubscriptionsListForAppID(bind.ApplicationID, logData)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subscriptions {
+		err = removeSubscription(sub.ID, sub.SVCInstanceID)
+		if err != nil {
+			log.Error("unable to remove the subscription", err, logData)
+			return &mapBrokerError.ErrorUnableToDeleteBind{}
+		}
+	}
+	return nil
+}
+
+// revertBindApplication deletes the Application of the given Bind and its stored subscriptions when the Bind fails.
+func revertBindApplication(bind *model.Bind, logData *log.Data) {
+	err := deleteBindApplication(bind, logData)
+	if err != nil {
+		log.Error("unable to revert the bind Application", err, logData)
+	}
+}
+
+// updateBindApplications applies the given set of APIs to the subscriptions of the Bind Applications of the given
+// service instance.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func updateBindApplications(svcInstance *model.ServiceInstance, apis []API, logData *log.Data) error {
+	binds, err := retrieveBindApplications(svcInstance.ID, logData)
+	if err != nil {
+		return err
+	}
+	for _, bind := range binds {
+		target := bindApplicationTarget(svcInstance, bind.ApplicationID)
+		existingAPIs, err := getExistingAPIsForAppID(bind.ApplicationID, logData)
+		if err != nil {
+			return err
+		}
+		err = updateServiceForChangedTiers(existingAPIs, apis, target, logData)
+		if err != nil {
+			return err
+		}
+		_, err = updateServiceForAddedAPIs(existingAPIs, apis, target, logData)
+		if err != nil {
+			return err
+		}
+		err = updateServiceForRemovedAPIs(existingAPIs, apis, target, logData)
+		if err != nil {
+			return err
+		}
+		err = updateSubscriptionSelectors(bind.ApplicationID, apis, logData)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retrieveBindApplications returns the Binds of the given instance which have a dedicated Application.
+func retrieveBindApplications(svcInstanceID string, logData *log.Data) ([]model.Bind, error) {
+	var binds []model.Bind
+	_, err := db.RetrieveList(&model.Bind{
+		SVCInstanceID:  svcInstanceID,
+		CredentialMode: CredentialModeBinding,
+	}, &binds)
+	if err != nil {
+		log.Error(ErrMsgUnableToGetBind, err, logData)
+		return nil, &mapBrokerError.ErrorUnableToRetrieveBind{}
+	}
+	return binds, nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wso2/openservicebroker-apim/pkg/apim"
 	"github.com/wso2/openservicebroker-apim/pkg/client"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
 	"github.com/wso2/openservicebroker-apim/pkg/db"
 	"github.com/wso2/openservicebroker-apim/pkg/log"
 	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
@@ -68,6 +69,8 @@ const (
 var (
 	ErrNotSupported                 = errors.New("not supported")
 	ErrInvalidSVCPlan               = errors.New("invalid service or getServices")
+	ErrInstanceNotFound             = apiresponses.NewFailureResponse(errors.New("instance not found"), http.StatusNotFound, "instance-not-found")
+	instanceLiveLookup              bool
 	applicationPlanBindable         = true
 	appPlanInputParameterSchema     map[string]interface{}
 	appPlanBindInputParameterSchema map[string]interface{}
@@ -78,24 +81,46 @@ type APIM struct{}
 
 // API struct represent an API.
 type API struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	ID       string `json:"id,omitempty"`
+	Context  string `json:"context,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Tier     string `json:"tier,omitempty"`
+	Follow   bool   `json:"follow,omitempty"`
+	// VersionSelector is the selector, e.g. "2.x", which the version of a resolved API is selected with.
+	VersionSelector string `json:"-" hash:"ignore"`
 }
 
 // ServiceParams represents the SVC create and update parameter.
 type ServiceParams struct {
 	APIs []API `json:"apis" hash:"set"`
+	// Application is the existing API-M Application adopted by the instance. It is only read on create.
+	Application *ApplicationRef `json:"application,omitempty"`
+	// Keys are the options of the keys generated for the Application. It is only read on create.
+	Keys *KeyParams `json:"keys,omitempty"`
+	// RotateCredentials regenerates the consumer secrets of the instance. It is only read on update.
+	RotateCredentials bool `json:"rotateCredentials,omitempty" hash:"ignore"`
+}
+
+// ApplicationRef references an existing Application in API-M by its ID or name.
+type ApplicationRef struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Keep leaves the Application in API-M when the instance is deleted
+	Keep bool `json:"keep,omitempty"`
 }
 
 // apimBrokerProvisionDetails represents the attributes retrieved from the provision request
 type apimBrokerProvisionDetails struct {
-	organizationalID  string
-	spaceID           string
+	serviceID         string
+	planID            string
+	platform          *platformContext
 	serviceParameters ServiceParams
 }
 
 // Init method initialize the API-M broker. If there is an error it will cause a panic.
-func (apimBroker *APIM) Init() {
+func (apimBroker *APIM) Init(conf *config.Broker) {
 	var err error
 	appPlanInputParameterSchema, err = utils.GetJSONSchema(apim.AppPlanInputParameterSchemaRaw)
 	if err != nil {
@@ -105,6 +130,19 @@ func (apimBroker *APIM) Init() {
 	if err != nil {
 		log.HandleErrorAndExit(fmt.Sprintf(ErrMsgUnableToGenBindInputSchema, "app"), err)
 	}
+	instanceLiveLookup = conf.Instance.LiveLookup
+	initAPIFollower(&conf.Instance)
+	initOrphanSweeper(&conf.Instance)
+	initReconciler(&conf.Reconcile)
+	initCredentialRotation(&conf.Rotation)
+	defaultCredentialMode = conf.Bind.CredentialMode
+	initCredentials(conf)
+	initCredentialTemplates(&conf.Bind)
+	initCatalog(&conf.Catalog)
+	initAPIPlan(conf.Catalog.APIPlan)
+	initSubscriptionPlan(conf.Catalog.SubscriptionPlan)
+	initOperationWorkers(&conf.Operation)
+	recoverSagas()
 }
 
 // Services returns the getServices offered(catalog) by this broker.
@@ -120,13 +158,18 @@ func createCommonLogData(svcInstanceID, serviceID, planID string) *log.Data {
 }
 
 func readProvisionDetails(serviceDetails *domain.ProvisionDetails, logData *log.Data) (*apimBrokerProvisionDetails, error) {
-	apiParams, err := getServiceParamsIfExists(serviceDetails.RawParameters, logData)
+	platform, err := readPlatformContext(serviceDetails, logData)
+	if err != nil {
+		return nil, err
+	}
+	apiParams, err := getProvisionServiceParams(serviceDetails.RawParameters, logData)
 	if err != nil {
 		return nil, err
 	}
 	provDetails := &apimBrokerProvisionDetails{
-		organizationalID:  serviceDetails.OrganizationGUID,
-		spaceID:           serviceDetails.SpaceGUID,
+		serviceID:         serviceDetails.ServiceID,
+		planID:            serviceDetails.PlanID,
+		platform:          platform,
 		serviceParameters: apiParams,
 	}
 	return provDetails, nil
@@ -142,6 +185,17 @@ func getServiceParamsIfExists(rawParams json.RawMessage, logData *log.Data) (Ser
 		log.Error("no APIs defined", nil, logData)
 		return apiParams, &mapBrokerError.ErrorEmptyAPIParameterSet{}
 	}
+	for _, api := range apiParams.APIs {
+		if !isIdentifiableAPI(api) {
+			log.Error("API is not identifiable", nil, logData)
+			return apiParams, &mapBrokerError.ErrorInvalidAPIParameter{}
+		}
+		err = validateVersionSelector(api)
+		if err != nil {
+			log.Error("invalid version selector: "+api.Version, err, logData)
+			return apiParams, err
+		}
+	}
 	return apiParams, nil
 }
 
@@ -149,8 +203,8 @@ func hasValidSpaceIDAndOrgID(spaceID string, orgID string) bool {
 	return spaceID != "" && orgID != ""
 }
 
-func validateHashSpaceIDOrgID(svcInstance *model.ServiceInstance, paramHash string, spaceID string, orgID string) bool {
-	return (svcInstance.ParameterHash == paramHash) && (svcInstance.SpaceID == spaceID) && (svcInstance.OrgID == orgID)
+func validateHashAndPlatformContext(svcInstance *model.ServiceInstance, paramHash string, platform *platformContext) bool {
+	return (svcInstance.ParameterHash == paramHash) && isSamePlatformContext(svcInstance, platform)
 }
 
 func generateHashForserviceParameters(appID string, svcParams ServiceParams, logData *log.Data) (string, error) {
@@ -164,17 +218,24 @@ func generateHashForserviceParameters(appID string, svcParams ServiceParams, log
 }
 
 func isSameInstanceWithDifferentAttrubutes(svcInstance *model.ServiceInstance, apimProvDetails *apimBrokerProvisionDetails, logData *log.Data) (bool, error) {
+	if instancePlanID(svcInstance) != apimProvDetails.planID {
+		return true, nil
+	}
 	parameterHash, err := generateHashForserviceParameters(svcInstance.ApplicationID, apimProvDetails.serviceParameters, logData)
 	if err != nil {
 		return false, err
 	}
-	if ok := validateHashSpaceIDOrgID(svcInstance, parameterHash, apimProvDetails.spaceID, apimProvDetails.organizationalID); ok {
+	if ok := validateHashAndPlatformContext(svcInstance, parameterHash, apimProvDetails.platform); ok {
 
 		existingAPIs, err := getExistingAPIsForAppID(svcInstance.ApplicationID, logData)
 		if err != nil {
 			return false, err
 		}
-		if !isSameAPIs(existingAPIs, apimProvDetails.serviceParameters.APIs) {
+		requestedAPIs, err := resolveAPIs(apimProvDetails.serviceParameters.APIs, logData)
+		if err != nil {
+			return false, err
+		}
+		if !isSameAPIs(existingAPIs, requestedAPIs) {
 			log.Debug("APIs does not match", logData)
 			return true, nil
 		}
@@ -186,7 +247,14 @@ func isSameInstanceWithDifferentAttrubutes(svcInstance *model.ServiceInstance, a
 func deleteSubscriptions(removedSubsIds []string, svcInstanceID string) error {
 
 	for _, sub := range removedSubsIds {
-		err := apim.UnSubscribe(sub)
+		_, err := journalStep(svcInstanceID, &model.SagaStep{
+			Action:     model.SagaStepDeleteSubscription,
+			ResourceID: sub,
+		})
+		if err != nil {
+			return err
+		}
+		err = apim.UnSubscribe(sub)
 		if err != nil {
 			return err
 		}
@@ -244,67 +312,210 @@ func getAddedAPIs(existingAPIs, updatedAPIs []API, logData *log.Data) []API {
 	return addedAPIs
 }
 
+// GetBinding returns the credentials and the parameters of the given Bind.
 func (apimBroker *APIM) GetBinding(ctx context.Context, svcInstanceID,
 	bindingID string) (domain.GetBindingSpec, error) {
-	return domain.GetBindingSpec{}, ErrNotSupported
+	logData := log.NewData().
+		Add(LogKeyInstanceID, svcInstanceID).
+		Add(LogKeyBindID, bindingID)
+
+	bind, err := retrieveServiceBind(bindingID, logData)
+	if err != nil {
+		return domain.GetBindingSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if bind == nil || bind.SVCInstanceID != svcInstanceID {
+		log.Debug("bind doesn't exists", logData)
+		return domain.GetBindingSpec{}, apiresponses.ErrBindingNotFound
+	}
+
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.GetBindingSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if svcInstance == nil {
+		return domain.GetBindingSpec{}, ErrInstanceNotFound
+	}
+
+	credentials, err := bindCredentials(bind, svcInstance, logData)
+	if err != nil {
+		return domain.GetBindingSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	var parameters interface{}
+	if bind.Parameters != "" {
+		parameters = json.RawMessage(bind.Parameters)
+	}
+	return domain.GetBindingSpec{
+		Credentials: credentials,
+		Parameters:  parameters,
+	}, nil
 }
 
+// GetInstance returns the service ID, plan ID, dashboard URL and the parameters of the given service instance.
 func (apimBroker *APIM) GetInstance(ctx context.Context,
 	svcInstanceID string) (domain.GetInstanceDetailsSpec, error) {
-	return domain.GetInstanceDetailsSpec{}, ErrNotSupported
+	logData := log.NewData().Add(LogKeyInstanceID, svcInstanceID)
+
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op != nil && op.Type == model.OperationProvision {
+		log.Debug("instance is being provisioned", logData)
+		return domain.GetInstanceDetailsSpec{}, ErrInstanceNotFound
+	}
+
+	apiInstance, err := retrieveAPIInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if apiInstance != nil {
+		return apiInstanceDetails(apiInstance), nil
+	}
+	subsInstance, err := retrieveSubscriptionInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if subsInstance != nil {
+		return subscriptionInstanceDetails(subsInstance), nil
+	}
+
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if svcInstance == nil {
+		return domain.GetInstanceDetailsSpec{}, ErrInstanceNotFound
+	}
+	logData.Add(LogKeyAppID, svcInstance.ApplicationID)
+
+	apis, err := getInstanceAPIs(svcInstance, logData)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+
+	return domain.GetInstanceDetailsSpec{
+		ServiceID:    instanceServiceID(svcInstance),
+		PlanID:       instancePlanID(svcInstance),
+		DashboardURL: apim.GetAppDashboardURL(svcInstance.ApplicationID),
+		Parameters: ServiceParams{
+			APIs: apis,
+		},
+	}, nil
+}
+
+// getInstanceAPIs returns the APIs subscribed by the given service instance. The subscriptions are read from API-M
+// if the live lookup is enabled and from the database otherwise.
+func getInstanceAPIs(svcInstance *model.ServiceInstance, logData *log.Data) ([]API, error) {
+	apis := []API{}
+	if instanceLiveLookup {
+		subsResponses, err := apim.ListSubscriptions(svcInstance.ApplicationID)
+		if err != nil {
+			log.Error("unable to list subscriptions from API-M", err, logData)
+			return nil, &mapBrokerError.ErrorUnableToRetrieveSubscriptionList{}
+		}
+		for _, sub := range getSubscriptionList(svcInstance.ID, subsResponses) {
+			apis = append(apis, subscriptionAPI(sub))
+		}
+		return apis, nil
+	}
+	subscriptionsList, err := getSubscriptionsListForAppID(svcInstance.ApplicationID, logData)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subscriptionsList {
+		api := subscriptionAPI(sub)
+		if api.VersionSelector != "" {
+			api.ID = ""
+			api.Version = api.VersionSelector
+		}
+		apis = append(apis, api)
+	}
+	return apis, nil
 }
 
+// LastBindingOperation returns the state of the given Bind. Binds are created synchronously, hence an existing Bind
+// is always reported as succeeded.
 func (apimBroker *APIM) LastBindingOperation(ctx context.Context, svcInstanceID,
 	bindingID string, details domain.PollDetails) (domain.LastOperation, error) {
-	return domain.LastOperation{}, ErrNotSupported
+	logData := createCommonLogData(svcInstanceID, details.ServiceID, details.PlanID)
+	logData.Add(LogKeyBindID, bindingID)
+
+	bind, err := retrieveServiceBind(bindingID, logData)
+	if err != nil {
+		return domain.LastOperation{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if bind == nil || bind.SVCInstanceID != svcInstanceID {
+		return domain.LastOperation{}, apiresponses.ErrBindingDoesNotExist
+	}
+	return domain.LastOperation{
+		State: domain.Succeeded,
+	}, nil
 }
 
 // getServices returns an array of getServices offered by this service broker and any error encountered.
 func getServices() ([]domain.Service, error) {
+	var plans []domain.ServicePlan
+	for _, plan := range activeApplicationPlans() {
+		plans = append(plans, domain.ServicePlan{
+			ID:          plan.id,
+			Name:        plan.name,
+			Description: plan.description,
+			Bindable:    &applicationPlanBindable,
+			Metadata: &domain.ServicePlanMetadata{
+				DisplayName: plan.displayName,
+				AdditionalMetadata: map[string]interface{}{
+					MetadataKeyThrottlingPolicy: plan.throttlingPolicy,
+				},
+			},
+			Schemas: &domain.ServiceSchemas{
+				Instance: domain.ServiceInstanceSchema{
+					Create: domain.Schema{
+						Parameters: appPlanInputParameterSchema,
+					},
+					Update: domain.Schema{
+						Parameters: appPlanInputParameterSchema,
+					},
+				},
+				Binding: domain.ServiceBindingSchema{
+					Create: domain.Schema{
+						Parameters: appPlanBindInputParameterSchema,
+					},
+				},
+			},
+		})
+	}
+	if apiPlanEnabled {
+		plans = append(plans, apiServicePlan())
+	}
+	if subscriptionPlanEnabled {
+		plans = append(plans, subscriptionServicePlan())
+	}
+	var metadata *domain.ServiceMetadata
+	if listCatalogAPIs {
+		metadata = &domain.ServiceMetadata{
+			AdditionalMetadata: map[string]interface{}{
+				MetadataKeyAPIs: getCatalogAPIs(),
+			},
+		}
+	}
 	return []domain.Service{
 		{
 			ID:                   ServiceID,
 			Name:                 ServiceName,
 			Description:          ServiceDescription,
 			Bindable:             true,
-			InstancesRetrievable: false,
+			InstancesRetrievable: true,
+			BindingsRetrievable:  true,
 			PlanUpdatable:        true,
-			Plans: []domain.ServicePlan{
-				{
-					ID:          ApplicationPlanID,
-					Name:        ApplicationPlanName,
-					Description: ApplicationPlanDescription,
-					Bindable:    &applicationPlanBindable,
-					Schemas: &domain.ServiceSchemas{
-						Instance: domain.ServiceInstanceSchema{
-							Create: domain.Schema{
-								Parameters: appPlanInputParameterSchema,
-							},
-							Update: domain.Schema{
-								Parameters: appPlanInputParameterSchema,
-							},
-						},
-						Binding: domain.ServiceBindingSchema{
-							Create: domain.Schema{
-								Parameters: appPlanBindInputParameterSchema,
-							},
-						},
-					},
-				},
-			},
+			Plans:                plans,
+			Metadata:             metadata,
 		},
 	}, nil
 }
 
-// createApplication creates Subscription in API-M and returns App ID, App dashboard URL and an error if encountered.
-func createApplication(appName string, logData *log.Data) (string, string, error) {
-	req := &apim.ApplicationCreateReq{
-		Name:             appName,
-		ThrottlingPolicy: "Unlimited",
-		Description:      "Application " + appName + " created by WSO2 APIM Service Broker",
-		TokenType: "OAUTH",
-	}
-	appID, err := apim.CreateApplication(req)
+// createApplication creates an Application with the given throttling policy in API-M and returns App ID, App dashboard URL and an error if encountered.
+func createApplication(appName, throttlingPolicy string, logData *log.Data) (string, string, error) {
+	appID, err := apim.CreateApplication(applicationReq(appName, throttlingPolicy))
 	if err != nil {
 		log.Error("unable to create application", err, logData)
 		return "", "", handleAPIMResourceCreateError(err, appName, logData)
@@ -344,6 +555,34 @@ func retriveServiceInstance(svcInstanceID string, logData *log.Data) (*model.Ser
 	return instance, nil
 }
 
+// isExistingInstance returns true if an instance of any plan exists with the given ID.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func isExistingInstance(svcInstanceID string, logData *log.Data) (bool, error) {
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil || svcInstance != nil {
+		return svcInstance != nil, err
+	}
+	apiInstance, err := retrieveAPIInstance(svcInstanceID, logData)
+	if err != nil || apiInstance != nil {
+		return apiInstance != nil, err
+	}
+	subsInstance, err := retrieveSubscriptionInstance(svcInstanceID, logData)
+	if err != nil {
+		return false, err
+	}
+	return subsInstance != nil, nil
+}
+
+// updateServiceInstanceRecord updates the given instance in the database. An error type mapped to apiresponses.FailureResponse is returned.
+func updateServiceInstanceRecord(i *model.ServiceInstance, logData *log.Data) error {
+	err := db.Update(i)
+	if err != nil {
+		log.Error("unable to update the instance in the database", err, logData)
+		return &mapBrokerError.ErrorUnableToStoreServiceInstance{}
+	}
+	return nil
+}
+
 // deleteInstance function deletes the given instance from database. An error type mapped to apiresponses.FailureResponse is returned.
 func deleteInstance(i *model.ServiceInstance, logData *log.Data) error {
 	err := db.Delete(i)
@@ -380,12 +619,25 @@ func getSubscriptionsListForAppID(applicationID string, logData *log.Data) ([]mo
 }
 
 func getSubscriptionForAppAndAPI(applicationID string, api API, logData *log.Data) (*model.Subscription, error) {
-	subscription := &model.Subscription{
-		ApplicationID: applicationID,
-		APIName:       api.Name,
-		APIVersion:    api.Version,
+	var subscription *model.Subscription
+	hasSubscription := false
+	var err error
+	if api.ID != "" {
+		subscription = &model.Subscription{
+			ApplicationID: applicationID,
+			APIID:         api.ID,
+		}
+		hasSubscription, err = db.Retrieve(subscription)
+	}
+	// Subscriptions created before the API IDs were stored can only be found by name and version.
+	if err == nil && !hasSubscription {
+		subscription = &model.Subscription{
+			ApplicationID: applicationID,
+			APIName:       api.Name,
+			APIVersion:    api.Version,
+		}
+		hasSubscription, err = db.Retrieve(subscription)
 	}
-	hasSubscription, err := db.Retrieve(subscription)
 	if err != nil {
 		log.Error("unable to retrieve subscription", err, logData)
 		return nil, &mapBrokerError.ErrorUnableToRetrieveSubscription{}
@@ -406,13 +658,10 @@ func getExistingAPIsForAppID(applicationID string, logData *log.Data) ([]API, er
 		log.Error("no subscriptions are available", err, logData)
 		return nil, &mapBrokerError.ErrorSubscriptionListUnavailable{}
 	}
+	backfillSubscriptionAPIIDs(subscriptionsList, logData)
 	var existingAPIs []API
 	for _, sub := range subscriptionsList {
-		api := API{
-			Name:    sub.APIName,
-			Version: sub.APIVersion,
-		}
-		existingAPIs = append(existingAPIs, api)
+		existingAPIs = append(existingAPIs, subscriptionAPI(sub))
 	}
 	return existingAPIs, nil
 }
@@ -426,12 +675,12 @@ func isSameAPIs(existingAPIs, requestedAPIs []API) bool {
 			return false
 		}
 	}
-	return true
+	return len(getTierChangedAPIs(existingAPIs, requestedAPIs)) == 0
 }
 
 func isArrayContainAPI(apis []API, api API) bool {
 	for _, a := range apis {
-		if a.Name == api.Name && a.Version == api.Version {
+		if isSameAPI(a, api) {
 			return true
 		}
 	}
@@ -457,6 +706,7 @@ func createAndStoreSubscriptions(instance *model.ServiceInstance, apis []API, lo
 	if err != nil {
 		unsubscribeMultipleAPIs(subscriptions, logData)
 		log.Error("unable to store subscriptions", err, logData)
+		return err
 	}
 
 	return nil
@@ -465,13 +715,22 @@ func createAndStoreSubscriptions(instance *model.ServiceInstance, apis []API, lo
 func createServiceInstanceObject(ID, paramHash string, apimProvDetails *apimBrokerProvisionDetails, appData *apim.ApplicationMetadata) *model.ServiceInstance {
 	svcInstance := &model.ServiceInstance{
 		ID:              ID,
+		ServiceID:       apimProvDetails.serviceID,
+		PlanID:          apimProvDetails.planID,
 		ApplicationID:   appData.ID,
 		ApplicationName: appData.Name,
-		SpaceID:         apimProvDetails.spaceID,
-		OrgID:           apimProvDetails.organizationalID,
+		SpaceID:         apimProvDetails.platform.identifiers[ContextKeySpaceID],
+		OrgID:           apimProvDetails.platform.identifiers[ContextKeyOrganizationID],
+		Platform:        apimProvDetails.platform.platform,
+		PlatformContext: apimProvDetails.platform.identifiersJSON(),
 		ConsumerKey:     appData.Keys.ConsumerKey,
 		ConsumerSecret:  appData.Keys.ConsumerSecret,
 		ParameterHash:   paramHash,
+		KeyParameters:   marshalKeyParams(apimProvDetails.serviceParameters.Keys),
+	}
+	if appRef := apimProvDetails.serviceParameters.Application; appRef != nil {
+		svcInstance.Adopted = true
+		svcInstance.KeepApplication = appRef.Keep
 	}
 	return svcInstance
 }
@@ -484,18 +743,27 @@ func persistServiceInstance(svcInstance *model.ServiceInstance, logData *log.Dat
 	return nil
 }
 
-func createApplicationAndGenerateKeys(id string, logData *log.Data) (*apim.ApplicationMetadata, error) {
+func createApplicationAndGenerateKeys(id, throttlingPolicy string, keyParams *KeyParams, logData *log.Data) (*apim.ApplicationMetadata, error) {
 	appName := generateApplicationName(id)
 
-	logData.Add(LogKeyApplicationName, appName)
-	appID, appDashboardURL, err := createApplication(appName, logData)
+	logData.Add(LogKeyApplicationName, appName).
+		Add(LogKeyThrottlingPolicy, throttlingPolicy)
+	step, err := journalStep(id, &model.SagaStep{
+		Action:       model.SagaStepCreateApplication,
+		ResourceName: appName,
+	})
 	if err != nil {
 		return nil, err
 	}
+	appID, appDashboardURL, err := createApplication(appName, throttlingPolicy, logData)
+	if err != nil {
+		return nil, err
+	}
+	completeStep(step, appID, logData)
 	logData.Add(LogKeyAppID, appID).
 		Add(ApplicationDashboardURL, appDashboardURL)
 
-	keys, err := generateKeysForApplication(appID, logData)
+	keys, err := generateKeysForApplication(appID, keyParams, logData)
 	if err != nil {
 		revertApplication(appID, logData)
 		return nil, err
@@ -542,6 +810,8 @@ func getSubscriptionList(svcInstanceID string, subsResponses []apim.Subscription
 			APIName:       subsResponse.ApiInfo.Name,
 			APIVersion:    subsResponse.ApiInfo.Version,
 			SVCInstanceID: svcInstanceID,
+			Tier:          subsResponse.ThrottlingPolicy,
+			APIID:         subsResponse.ApiID,
 		}
 		subscriptions = append(subscriptions, subs)
 	}
@@ -561,26 +831,52 @@ func storeServiceInstance(i *model.ServiceInstance, logData *log.Data) error {
 func createSubscriptions(svcInstance *model.ServiceInstance, apis []API, logData *log.Data) ([]model.Subscription, error) {
 
 	var subscriptionRequests []apim.SubscriptionReq
+	var resolvedAPIs []API
 
 	for _, api := range apis {
-		apiID, err := apim.SearchAPIByNameVersion(api.Name, api.Version)
+		var err error
+		if api.ID == "" {
+			api, err = resolveAPI(api, logData)
+			if err != nil {
+				return nil, err
+			}
+		}
+		resolvedAPIs = append(resolvedAPIs, api)
+		err = validateSubscriptionTier(api.ID, api, logData)
 		if err != nil {
-			return nil, &mapBrokerError.ErrorUnableToSearchAPIs{}
+			return nil, err
 		}
 		subReq := apim.SubscriptionReq{
 			ApplicationID:    svcInstance.ApplicationID,
-			ApiID:            apiID,
-			ThrottlingPolicy: "Unlimited",
+			ApiID:            api.ID,
+			ThrottlingPolicy: subscriptionTier(api),
 		}
 		subscriptionRequests = append(subscriptionRequests, subReq)
 	}
 
+	steps := make(map[string]*model.SagaStep)
+	for _, subReq := range subscriptionRequests {
+		step, err := journalStep(svcInstance.ID, &model.SagaStep{
+			Action:        model.SagaStepCreateSubscription,
+			ApplicationID: subReq.ApplicationID,
+			APIID:         subReq.ApiID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		steps[subReq.ApiID] = step
+	}
 	subscriptionCreateResp, err := apim.CreateMultipleSubscriptions(subscriptionRequests)
 	if err != nil {
 		log.Error("unable to create subscriptions", err, logData)
 		return nil, &mapBrokerError.ErrorUnableToCreateSubscription{}
 	}
-	return getSubscriptionList(svcInstance.ID, subscriptionCreateResp), nil
+	for _, subResp := range subscriptionCreateResp {
+		completeStep(steps[subResp.ApiID], subResp.SubscriptionID, logData)
+	}
+	subscriptions := getSubscriptionList(svcInstance.ID, subscriptionCreateResp)
+	setVersionSelectors(subscriptions, resolvedAPIs)
+	return subscriptions, nil
 }
 
 func generateApplicationName(svcInstanceID string) string {
@@ -589,21 +885,65 @@ func generateApplicationName(svcInstanceID string) string {
 
 func (apimBroker *APIM) Provision(ctx context.Context, svcInstanceID string,
 	provisionDetails domain.ProvisionDetails, asyncAllowed bool) (domain.ProvisionedServiceSpec, error) { //pdfProvisionDetails
-	if !hasValidSpaceIDAndOrgID(provisionDetails.SpaceGUID, provisionDetails.OrganizationGUID) {
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(errors.New("check space ID and org ID"), http.StatusBadRequest, "invalid parameters")
+	logData := createCommonLogData(svcInstanceID, provisionDetails.ServiceID, provisionDetails.PlanID)
+
+	if isAPIPlan(provisionDetails.PlanID) {
+		return provisionAPIPlan(svcInstanceID, &provisionDetails, asyncAllowed, logData)
+	}
+	if isSubscriptionPlan(provisionDetails.PlanID) {
+		return provisionSubscriptionPlan(svcInstanceID, &provisionDetails, asyncAllowed, logData)
 	}
 
-	logData := createCommonLogData(svcInstanceID, provisionDetails.ServiceID, provisionDetails.PlanID)
+	err := validatePlan(provisionDetails.PlanID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
 
 	apimProvDetails, err := readProvisionDetails(&provisionDetails, logData)
 	if err != nil {
 		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
 
+	paramHash, err := generateHashForserviceParameters("", apimProvDetails.serviceParameters, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	requestHash, err := generateProvisionRequestHash(apimProvDetails.planID, paramHash, apimProvDetails.platform, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op != nil {
+		return provisionInProgress(op, requestHash, asyncAllowed)
+	}
+	lock, err := lockInstance(svcInstanceID, model.OperationProvision, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	defer lock.release()
+
 	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
 	if err != nil {
 		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
+	apiInstance, err := retrieveAPIInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if apiInstance != nil {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+	subsInstance, err := retrieveSubscriptionInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if subsInstance != nil {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
 
 	if svcInstance != nil {
 		confirm, err := isSameInstanceWithDifferentAttrubutes(svcInstance, apimProvDetails, logData)
@@ -620,46 +960,143 @@ func (apimBroker *APIM) Provision(ctx context.Context, svcInstanceID string,
 
 	}
 
-	appMetadata, err := createApplicationAndGenerateKeys(svcInstanceID, logData)
+	if asyncAllowed {
+		op, err := startRequestOperation(svcInstanceID, model.OperationProvision, requestHash, func() error {
+			_, err := provisionServiceInstance(svcInstanceID, apimProvDetails, logData)
+			return err
+		}, logData)
+		if err != nil {
+			return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.ProvisionedServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	dashboardURL, err := provisionServiceInstance(svcInstanceID, apimProvDetails, logData)
 	if err != nil {
 		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
+	return domain.ProvisionedServiceSpec{
+		DashboardURL: dashboardURL,
+	}, nil
+}
+
+// provisionServiceInstance creates the Application, keys and subscriptions in API-M and stores the service instance.
+// Returns the Application dashboard URL and any error encountered.
+func provisionServiceInstance(svcInstanceID string, apimProvDetails *apimBrokerProvisionDetails, logData *log.Data) (string, error) {
+	apis, err := resolveAPIs(apimProvDetails.serviceParameters.APIs, logData)
+	if err != nil {
+		return "", err
+	}
+	throttlingPolicy, err := throttlingPolicyForPlan(apimProvDetails.planID, logData)
+	if err != nil {
+		return "", err
+	}
+	s, err := beginSaga(svcInstanceID, model.OperationProvision, logData)
+	if err != nil {
+		return "", err
+	}
+	defer s.end()
+	appRef := apimProvDetails.serviceParameters.Application
+	var appMetadata *apim.ApplicationMetadata
+	if appRef != nil {
+		appMetadata, err = adoptApplication(appRef, apimProvDetails.serviceParameters.Keys, logData)
+	} else {
+		appMetadata, err = createApplicationAndGenerateKeys(svcInstanceID, throttlingPolicy,
+			apimProvDetails.serviceParameters.Keys, logData)
+	}
+	if err != nil {
+		return "", err
+	}
 
 	parameterHash, err := generateHashForserviceParameters(appMetadata.ID, apimProvDetails.serviceParameters, logData)
 	if err != nil {
-		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		revertProvisionedApplication(appMetadata.ID, appRef, logData)
+		return "", err
 	}
 
-	svcInstance = createServiceInstanceObject(svcInstanceID, parameterHash, apimProvDetails, appMetadata)
+	svcInstance := createServiceInstanceObject(svcInstanceID, parameterHash, apimProvDetails, appMetadata)
+	svcInstance.ThrottlingPolicy = throttlingPolicy
 
 	err = persistServiceInstance(svcInstance, logData)
 	if err != nil {
-		revertApplication(appMetadata.ID, logData)
-		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		revertProvisionedApplication(appMetadata.ID, appRef, logData)
+		return "", err
+	}
+
+	if appRef != nil {
+		apis, err = importSubscriptions(svcInstance, apis, logData)
+		if err != nil {
+			removeServiceInstanceAndLogError(svcInstanceID, logData)
+			return "", err
+		}
+		if len(apis) == 0 {
+			return appMetadata.DashboardURL, nil
+		}
 	}
 
-	err = createAndStoreSubscriptions(svcInstance, apimProvDetails.serviceParameters.APIs, logData)
+	err = createAndStoreSubscriptions(svcInstance, apis, logData)
 	if err != nil {
-		revertApplication(appMetadata.ID, logData)
+		revertProvisionedApplication(appMetadata.ID, appRef, logData)
 		removeServiceInstanceAndLogError(svcInstanceID, logData)
-		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		return "", err
 	}
-
-	return domain.ProvisionedServiceSpec{
-		DashboardURL: appMetadata.DashboardURL,
-	}, nil
+	return appMetadata.DashboardURL, nil
 }
 
 func (apimBroker *APIM) Deprovision(ctx context.Context, svcInstanceID string,
 	serviceDetails domain.DeprovisionDetails, asyncAllowed bool) (domain.DeprovisionServiceSpec, error) {
 	logData := createCommonLogData(svcInstanceID, serviceDetails.ServiceID, serviceDetails.PlanID)
 
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op != nil {
+		if asyncAllowed && op.Type == model.OperationDeprovision {
+			return domain.DeprovisionServiceSpec{
+				IsAsync:       true,
+				OperationData: op.ID,
+			}, nil
+		}
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(&mapBrokerError.ErrorOperationInProgress{})
+	}
+	lock, err := lockInstance(svcInstanceID, model.OperationDeprovision, logData)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	defer lock.release()
+
+	apiInstance, err := retrieveAPIInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if apiInstance != nil {
+		return deprovisionAPIPlan(apiInstance, asyncAllowed, logData)
+	}
+	subsInstance, err := retrieveSubscriptionInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if subsInstance != nil {
+		return deprovisionSubscriptionPlan(subsInstance, asyncAllowed, logData)
+	}
+
 	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
 	if err != nil {
 		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
 	if svcInstance == nil {
 		log.Debug("instance doesn't exists", logData)
+		deleted, err := deleteOrphanApplication(svcInstanceID, logData)
+		if err != nil {
+			return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		if deleted {
+			return domain.DeprovisionServiceSpec{}, nil
+		}
 		return domain.DeprovisionServiceSpec{}, apiresponses.ErrInstanceDoesNotExist
 	}
 
@@ -667,23 +1104,46 @@ func (apimBroker *APIM) Deprovision(ctx context.Context, svcInstanceID string,
 		Add(LogKeyAppID, svcInstance.ApplicationID).
 		Add(LogKeyApplicationName, svcInstance.ApplicationName)
 
-	log.Debug("delete the application", logData)
-	err = apim.DeleteApplication(svcInstance.ApplicationID)
-	if err != nil {
-		log.Error("unable to delete the Application", err, logData)
-		return domain.DeprovisionServiceSpec{}, apiresponses.NewFailureResponse(errors.New(ErrMsgUnableDelInstance), http.StatusInternalServerError, ErrActionDelAPP)
+	if asyncAllowed {
+		op, err := startOperation(svcInstanceID, model.OperationDeprovision, func() error {
+			return deprovisionServiceInstance(svcInstance, logData)
+		}, logData)
+		if err != nil {
+			return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.DeprovisionServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
 	}
 
-	log.Debug(DebugMsgDelInstance, logData)
-
-	err = deleteInstance(&model.ServiceInstance{ID: svcInstanceID}, logData)
+	err = deprovisionServiceInstance(svcInstance, logData)
 	if err != nil {
 		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
-
 	return domain.DeprovisionServiceSpec{}, nil
 }
 
+// deprovisionServiceInstance deletes the Application in API-M, unless an adopted Application is kept, and removes the
+// service instance from the database.
+// Returns any error encountered.
+func deprovisionServiceInstance(svcInstance *model.ServiceInstance, logData *log.Data) error {
+	if svcInstance.KeepApplication {
+		log.Debug("keep the adopted application", logData)
+	} else {
+		log.Debug("delete the application", logData)
+		err := apim.DeleteApplication(svcInstance.ApplicationID)
+		if err != nil {
+			log.Error("unable to delete the Application", err, logData)
+			return apiresponses.NewFailureResponse(errors.New(ErrMsgUnableDelInstance), http.StatusInternalServerError, ErrActionDelAPP)
+		}
+	}
+
+	log.Debug(DebugMsgDelInstance, logData)
+
+	return deleteInstance(&model.ServiceInstance{ID: svcInstance.ID}, logData)
+}
+
 func unmarshalServiceParams(rawParam json.RawMessage) (ServiceParams, error) {
 	var serviceParams ServiceParams
 	err := json.Unmarshal(rawParam, &serviceParams)
@@ -711,8 +1171,9 @@ func retrieveServiceBind(bindingID string, logData *log.Data) (*model.Bind, erro
 }
 
 // isBindWithSameAttributes returns true of the Bind is already exists and attached with the given instance ID,attributes.
-func isBindWithSameAttributes(bind *model.Bind, svcInstanceID string, bindResource *domain.BindResource) bool {
-	var isSameAttributes = svcInstanceID == bind.SVCInstanceID
+func isBindWithSameAttributes(bind *model.Bind, svcInstanceID string, bindResource *domain.BindResource, bindParams BindParams) bool {
+	var isSameAttributes = svcInstanceID == bind.SVCInstanceID && credentialModeOf(bind) == bindParams.CredentialMode &&
+		credentialsVersionOf(bind) == bindParams.CredentialsVersion && bind.CredentialTemplate == bindParams.CredentialTemplate
 	if !isOriginatedFromCreateServiceKey(bindResource) {
 		isSameAttributes = isSameAttributes && (bindResource.AppGuid == bind.PlatformAppID)
 	}
@@ -726,6 +1187,12 @@ func (apimBroker *APIM) Bind(ctx context.Context, svcInstanceID, bindingID strin
 	logData := createCommonLogData(svcInstanceID, bindDetails.ServiceID, bindDetails.PlanID)
 	logData.Add(LogKeyBindID, bindingID)
 
+	bindParams, err := getBindParams(bindDetails.RawParameters, logData)
+	if err != nil {
+		return domain.Binding{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	logData.Add(LogKeyCredentialMode, bindParams.CredentialMode)
+
 	bind, err := retrieveServiceBind(bindingID, logData)
 	if err != nil {
 		return domain.Binding{}, mapBrokerError.MapBrokerErrors(err)
@@ -740,17 +1207,22 @@ func (apimBroker *APIM) Bind(ctx context.Context, svcInstanceID, bindingID strin
 		log.Debug("instance doesn't exists", logData)
 		return domain.Binding{}, apiresponses.ErrInstanceDoesNotExist
 	}
-
-	credentialsMap := credentialsMap(svcInstance.ApplicationName, svcInstance.ConsumerKey, svcInstance.ConsumerSecret)
+	if bindParams.CredentialTemplate == "" {
+		bindParams.CredentialTemplate = credentialTemplateForPlan(instancePlanID(svcInstance))
+	}
 
 	var isWithSameAttr = false
 	if bind != nil {
-		isWithSameAttr = isBindWithSameAttributes(bind, svcInstanceID, bindDetails.BindResource)
+		isWithSameAttr = isBindWithSameAttributes(bind, svcInstanceID, bindDetails.BindResource, bindParams)
 		if !isWithSameAttr {
 			return domain.Binding{}, apiresponses.ErrBindingAlreadyExists
 		}
+		credentials, err := bindCredentials(bind, svcInstance, logData)
+		if err != nil {
+			return domain.Binding{}, mapBrokerError.MapBrokerErrors(err)
+		}
 		return domain.Binding{
-			Credentials:   credentialsMap,
+			Credentials:   credentials,
 			AlreadyExists: true,
 		}, nil
 	}
@@ -759,22 +1231,52 @@ func (apimBroker *APIM) Bind(ctx context.Context, svcInstanceID, bindingID strin
 	logData.Add(LogKeyPlatformApplicationName, platformAppID)
 
 	bind = &model.Bind{
-		ID:            bindingID,
-		PlatformAppID: platformAppID,
-		SVCInstanceID: svcInstanceID,
+		ID:                 bindingID,
+		PlatformAppID:      platformAppID,
+		SVCInstanceID:      svcInstanceID,
+		Parameters:         string(bindDetails.RawParameters),
+		CredentialMode:     bindParams.CredentialMode,
+		CredentialsVersion: bindParams.CredentialsVersion,
+		CredentialTemplate: bindParams.CredentialTemplate,
+	}
+	if bindParams.CredentialMode == CredentialModeBinding {
+		err = createBindApplication(bind, svcInstance, bindParams.Keys, logData)
+		if err != nil {
+			return domain.Binding{}, mapBrokerError.MapBrokerErrors(err)
+		}
+	}
+	credentials, err := bindCredentials(bind, svcInstance, logData)
+	if err == nil {
+		err = storeBind(bind, logData)
 	}
-	err = storeBind(bind, logData)
 	if err != nil {
+		if bind.ApplicationID != "" {
+			revertBindApplication(bind, logData)
+		}
 		return domain.Binding{}, mapBrokerError.MapBrokerErrors(err)
 	}
 	log.Debug("successfully stored the Bind", logData)
 	return domain.Binding{
-		Credentials: credentialsMap,
+		Credentials: credentials,
 	}, nil
 }
 
 func isApplicationPlan(planID string) bool {
-	return planID == ApplicationPlanID
+	return getApplicationPlan(planID) != nil
+}
+
+// isApplicationPlanOfInstance returns true if the given plan ID is an Application plan or the plan of the given service
+// instance, so that the binds of an instance whose plan is removed or not loaded yet can still be deleted.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func isApplicationPlanOfInstance(svcInstanceID, planID string, logData *log.Data) (bool, error) {
+	if isApplicationPlan(planID) {
+		return true, nil
+	}
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil {
+		return false, err
+	}
+	return svcInstance != nil && instancePlanID(svcInstance) == planID, nil
 }
 
 func getPlatformAppID(b *domain.BindResource) string {
@@ -795,10 +1297,10 @@ func revertApplication(appID string, logData *log.Data) {
 	log.Debug("Delete Application", logData)
 }
 
-// generateKeysForApplication function generates keys for the given Subscription.
+// generateKeysForApplication function generates keys for the given Application with the given key parameters.
 // Returns generated keys and an error type mapped to apiresponses.FailureResponse if encountered.
-func generateKeysForApplication(appID string, logData *log.Data) (*apim.ApplicationKeyResp, error) {
-	appKeys, err := apim.GenerateKeys(appID)
+func generateKeysForApplication(appID string, keyParams *KeyParams, logData *log.Data) (*apim.ApplicationKeyResp, error) {
+	appKeys, err := apim.GenerateKeys(appID, keyGenerateRequest(keyParams))
 	if err != nil {
 		log.Error(ErrMsgUnableGenerateKeys, err, logData)
 		return appKeys, &mapBrokerError.ErrorUnableToGenerateKeys{}
@@ -837,7 +1339,11 @@ func (apimBroker *APIM) Unbind(ctx context.Context, svcInstanceID, bindingID str
 
 	logData := createCommonLogData(svcInstanceID, unbindDetails.ServiceID, unbindDetails.PlanID)
 
-	if !isApplicationPlan(unbindDetails.PlanID) {
+	validPlan, err := isApplicationPlanOfInstance(svcInstanceID, unbindDetails.PlanID, logData)
+	if err != nil {
+		return domain.UnbindSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if !validPlan {
 		log.Error(ErrMsgInvalidPlanID, ErrInvalidSVCPlan, logData)
 		return domain.UnbindSpec{}, apiresponses.NewFailureResponse(errors.New("unbinding"), http.StatusBadRequest, "invalid planID")
 	}
@@ -852,6 +1358,13 @@ func (apimBroker *APIM) Unbind(ctx context.Context, svcInstanceID, bindingID str
 
 	logData.Add("cf-app-id", bind.PlatformAppID)
 
+	if credentialModeOf(bind) == CredentialModeBinding {
+		err = deleteBindApplication(bind, logData)
+		if err != nil {
+			return domain.UnbindSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+	}
+
 	err = deleteBind(bind, logData)
 	if err != nil {
 		return domain.UnbindSpec{}, mapBrokerError.MapBrokerErrors(err)
@@ -869,12 +1382,28 @@ func deleteBind(bind *model.Bind, logData *log.Data) error {
 	return nil
 }
 
-// LastOperation ...
+// LastOperation returns the state of the last asynchronous operation of the given service instance.
 // If the broker provisions asynchronously, the Cloud Controller will poll this endpoint
 // for the status of the provisioning operation.
 func (apimBroker *APIM) LastOperation(ctx context.Context, svcInstanceID string,
 	details domain.PollDetails) (domain.LastOperation, error) {
-	return domain.LastOperation{}, errors.New("not supported")
+	logData := createCommonLogData(svcInstanceID, details.ServiceID, details.PlanID)
+	logData.Add(LogKeyOperationID, details.OperationData)
+
+	op, err := retrieveOperation(svcInstanceID, details.OperationData, logData)
+	if err != nil {
+		return domain.LastOperation{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op == nil {
+		log.Debug("operation doesn't exists", logData)
+		return domain.LastOperation{}, apiresponses.ErrInstanceDoesNotExist
+	}
+	expireOperationIfTimedOut(op, logData)
+
+	return domain.LastOperation{
+		State:       domain.LastOperationState(op.State),
+		Description: op.Description,
+	}, nil
 }
 
 func updateServiceForAddedAPIs(existingAPIs, updatedAPIs []API, svcInstance *model.ServiceInstance, logData *log.Data) ([]API, error) {
@@ -930,10 +1459,33 @@ func (apimBroker *APIM) Update(cxt context.Context, svcInstanceID string,
 	logData := createCommonLogData(svcInstanceID, updateDetails.ServiceID, updateDetails.PlanID)
 	log.Debug("update service instance", logData)
 
-	svcParams, err := getServiceParamsIfExists(updateDetails.RawParameters, logData)
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(&mapBrokerError.ErrorOperationInProgress{})
+	}
+	lock, err := lockInstance(svcInstanceID, model.OperationUpdate, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	defer lock.release()
+
+	apiInstance, err := retrieveAPIInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if apiInstance != nil {
+		return updateAPIPlan(apiInstance, &updateDetails, asyncAllowed, logData)
+	}
+	subsInstance, err := retrieveSubscriptionInstance(svcInstanceID, logData)
 	if err != nil {
 		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
+	if subsInstance != nil {
+		return updateSubscriptionPlan(subsInstance, &updateDetails, asyncAllowed, logData)
+	}
 
 	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
 	if err != nil {
@@ -944,23 +1496,146 @@ func (apimBroker *APIM) Update(cxt context.Context, svcInstanceID string,
 		return domain.UpdateServiceSpec{}, apiresponses.ErrInstanceDoesNotExist
 	}
 
-	existingAPIs, err := getExistingAPIsForAppID(svcInstance.ApplicationID, logData)
+	// A retired plan is only rejected when moving to it, instances already on it can still be updated
+	if updateDetails.PlanID != "" && updateDetails.PlanID != instancePlanID(svcInstance) {
+		err := validatePlan(updateDetails.PlanID, logData)
+		if err != nil {
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+	}
+
+	svcParams, err := getUpdateServiceParams(&updateDetails, svcInstance, logData)
 	if err != nil {
 		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
 
-	addedAPIs, err := updateServiceForAddedAPIs(existingAPIs, svcParams.APIs, svcInstance, logData)
+	if asyncAllowed {
+		op, err := startOperation(svcInstanceID, model.OperationUpdate, func() error {
+			return updateServiceInstance(svcInstance, updateDetails.PlanID, svcParams, logData)
+		}, logData)
+		if err != nil {
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.UpdateServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err = updateServiceInstance(svcInstance, updateDetails.PlanID, svcParams, logData)
 	if err != nil {
 		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
 	}
+	return domain.UpdateServiceSpec{}, nil
+}
 
-	err = updateServiceForRemovedAPIs(existingAPIs, svcParams.APIs, svcInstance, logData)
+// getUpdateServiceParams returns the service parameters of the update request. If the request only changes the plan
+// or rotates the credentials, the APIs of the given instance are kept.
+func getUpdateServiceParams(updateDetails *domain.UpdateDetails, svcInstance *model.ServiceInstance, logData *log.Data) (ServiceParams, error) {
+	planChangeOnly := len(updateDetails.RawParameters) == 0 && updateDetails.PlanID != "" && updateDetails.PlanID != instancePlanID(svcInstance)
+	rotationOnly := isCredentialRotationOnly(updateDetails.RawParameters)
+	if planChangeOnly || rotationOnly {
+		existingAPIs, err := getExistingAPIsForAppID(svcInstance.ApplicationID, logData)
+		if err != nil {
+			return ServiceParams{}, err
+		}
+		return ServiceParams{APIs: existingAPIs, RotateCredentials: rotationOnly}, nil
+	}
+	return getServiceParamsIfExists(updateDetails.RawParameters, logData)
+}
+
+// updateServiceInstance applies the requested plan and set of APIs to the given service instance.
+// Returns any error encountered.
+func updateServiceInstance(svcInstance *model.ServiceInstance, planID string, svcParams ServiceParams, logData *log.Data) error {
+	existingAPIs, err := getExistingAPIsForAppID(svcInstance.ApplicationID, logData)
 	if err != nil {
-		revertAddedAPIs(svcInstance.ApplicationID, svcInstanceID, addedAPIs, logData)
-		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		return err
+	}
+
+	requestedAPIs, err := resolveAPIs(svcParams.APIs, logData)
+	if err != nil {
+		return err
+	}
+
+	s, err := beginSaga(svcInstance.ID, model.OperationUpdate, logData)
+	if err != nil {
+		return err
+	}
+	defer s.end()
+
+	err = updateServiceForChangedTiers(existingAPIs, requestedAPIs, svcInstance, logData)
+	if err != nil {
+		return err
+	}
+
+	addedAPIs, err := updateServiceForAddedAPIs(existingAPIs, requestedAPIs, svcInstance, logData)
+	if err != nil {
+		return err
+	}
+
+	err = updateServiceForRemovedAPIs(existingAPIs, requestedAPIs, svcInstance, logData)
+	if err != nil {
+		revertAddedAPIs(svcInstance.ApplicationID, svcInstance.ID, addedAPIs, logData)
+		return err
+	}
+
+	err = updateSubscriptionSelectors(svcInstance.ApplicationID, requestedAPIs, logData)
+	if err != nil {
+		return err
+	}
+
+	err = updateBindApplications(svcInstance, requestedAPIs, logData)
+	if err != nil {
+		return err
+	}
+
+	if planID != "" && planID != instancePlanID(svcInstance) {
+		err = updateServiceInstancePlan(svcInstance, planID, logData)
+		if err != nil {
+			return err
+		}
+	}
+
+	if svcParams.RotateCredentials {
+		err = rotateInstanceCredentials(svcInstance, logData)
+		if err != nil {
+			return err
+		}
 	}
 
 	log.Debug("Instace successfully updated", logData)
+	return nil
+}
 
-	return domain.UpdateServiceSpec{}, nil
+// updateServiceInstancePlan moves the Applications of the given instance and its Binds to the throttling policy of
+// the given plan and stores the new plan of the instance.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func updateServiceInstancePlan(svcInstance *model.ServiceInstance, planID string, logData *log.Data) error {
+	throttlingPolicy, err := throttlingPolicyForPlan(planID, logData)
+	if err != nil {
+		return err
+	}
+	currentPolicy, err := instanceThrottlingPolicy(svcInstance, logData)
+	if err != nil {
+		return err
+	}
+	if throttlingPolicy != currentPolicy {
+		err = updateApplicationThrottlingPolicy(svcInstance.ApplicationID, svcInstance.ApplicationName, throttlingPolicy, logData)
+		if err != nil {
+			return err
+		}
+		binds, err := retrieveBindApplications(svcInstance.ID, logData)
+		if err != nil {
+			return err
+		}
+		for _, bind := range binds {
+			err = updateApplicationThrottlingPolicy(bind.ApplicationID, bind.ApplicationName, throttlingPolicy, logData)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	svcInstance.PlanID = planID
+	svcInstance.ThrottlingPolicy = throttlingPolicy
+	return updateServiceInstanceRecord(svcInstance, logData)
 }
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+)
+
+const (
+	MetadataKeyAPIs             = "apis"
+	MetadataKeyThrottlingPolicy = "throttlingPolicy"
+)
+
+// CatalogAPI represents an API listed in the service metadata of the catalog.
+type CatalogAPI struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Context  string `json:"context"`
+	Provider string `json:"provider"`
+}
+
+var (
+	dynamicCatalog  bool
+	listCatalogAPIs bool
+	catalogAPIs     []CatalogAPI
+	catalogAPIsLock sync.RWMutex
+)
+
+// initCatalog initializes the Application plans and, if the catalog is built from API-M, loads the catalog and starts
+// refreshing it periodically with the configured interval.
+func initCatalog(conf *config.Catalog) {
+	initApplicationPlans(conf)
+	dynamicCatalog = conf.Dynamic
+	listCatalogAPIs = conf.ListAPIs
+	if !dynamicCatalog && !listCatalogAPIs {
+		return
+	}
+	refreshCatalog()
+	if conf.RefreshInterval > 0 {
+		go refreshCatalogPeriodically(time.Duration(conf.RefreshInterval) * time.Second)
+	}
+}
+
+// refreshCatalogPeriodically refreshes the catalog with the given interval.
+func refreshCatalogPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		refreshCatalog()
+	}
+}
+
+// refreshCatalog reloads the Application throttling policies and the APIs from API-M. If API-M can't be reached the
+// previously loaded catalog is kept.
+func refreshCatalog() {
+	logData := log.NewData()
+	if dynamicCatalog {
+		policies, err := apim.ListApplicationThrottlingPolicies()
+		if err != nil {
+			log.Error("unable to retrieve the Application throttling policies", err, logData)
+		} else {
+			setDynamicPlans(policies)
+		}
+	}
+	if listCatalogAPIs {
+		apis, err := apim.ListAPIs()
+		if err != nil {
+			log.Error("unable to retrieve the APIs", err, logData)
+		} else {
+			setCatalogAPIs(apis)
+		}
+	}
+	log.Debug("catalog refreshed", logData)
+}
+
+// setCatalogAPIs replaces the APIs listed in the catalog with the given APIs.
+func setCatalogAPIs(apis []apim.StoreAPIInfo) {
+	list := make([]CatalogAPI, 0, len(apis))
+	for _, api := range apis {
+		list = append(list, CatalogAPI{
+			Name:     api.Name,
+			Version:  api.Version,
+			Context:  api.Context,
+			Provider: api.Provider,
+		})
+	}
+	catalogAPIsLock.Lock()
+	defer catalogAPIsLock.Unlock()
+	catalogAPIs = list
+}
+
+// getCatalogAPIs returns the APIs listed in the catalog.
+func getCatalogAPIs() []CatalogAPI {
+	catalogAPIsLock.RLock()
+	defer catalogAPIsLock.RUnlock()
+	return catalogAPIs
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	LogKeyCredentialTemplate        = "credential-template"
+	ErrMsgInvalidCredentialTemplate = "invalid credential template configuration"
+)
+
+var (
+	credentialTemplates       map[string]*template.Template
+	defaultCredentialTemplate string
+	credentialTemplateFuncs   = template.FuncMap{
+		"json": templateJSON,
+	}
+)
+
+// CredentialTemplateData is the data a credential template is executed with.
+type CredentialTemplateData struct {
+	Instance      *model.ServiceInstance
+	Bind          *model.Bind
+	Keys          CredentialKeys
+	Subscriptions []model.Subscription
+	// Credentials are the credentials of the Bind without a template
+	Credentials map[string]interface{}
+}
+
+// CredentialKeys represents the Application and keys used by a Bind.
+type CredentialKeys struct {
+	ApplicationName string
+	ConsumerKey     string
+	ConsumerSecret  string
+}
+
+// initCredentialTemplates parses the configured credential templates. If there is an error it will cause a panic.
+func initCredentialTemplates(conf *config.Bind) {
+	credentialTemplates = make(map[string]*template.Template)
+	for _, t := range conf.Templates {
+		if t.Name == "" || t.Template == "" {
+			log.HandleErrorAndExit(ErrMsgInvalidCredentialTemplate,
+				errors.New("name and template are required for the credential template: "+t.Name))
+		}
+		if credentialTemplates[t.Name] != nil {
+			log.HandleErrorAndExit(ErrMsgInvalidCredentialTemplate, errors.New("duplicate credential template: "+t.Name))
+		}
+		tmpl, err := template.New(t.Name).Funcs(credentialTemplateFuncs).Option("missingkey=error").Parse(t.Template)
+		if err != nil {
+			log.HandleErrorAndExit(ErrMsgInvalidCredentialTemplate, err)
+		}
+		credentialTemplates[t.Name] = tmpl
+	}
+	if !isCredentialTemplate(conf.CredentialTemplate) {
+		log.HandleErrorAndExit(ErrMsgInvalidCredentialTemplate,
+			errors.New("unknown bind.credentialTemplate: "+conf.CredentialTemplate))
+	}
+	defaultCredentialTemplate = conf.CredentialTemplate
+}
+
+// isCredentialTemplate returns true if the given name is a configured credential template or empty, which means no
+// template.
+func isCredentialTemplate(name string) bool {
+	return name == "" || credentialTemplates[name] != nil
+}
+
+// credentialTemplateForPlan returns the default credential template of the binds of the given plan.
+func credentialTemplateForPlan(planID string) string {
+	plan := getApplicationPlan(planID)
+	if plan == nil || plan.credentialTemplate == "" {
+		return defaultCredentialTemplate
+	}
+	return plan.credentialTemplate
+}
+
+// applyCredentialTemplate renders the given credentials with the credential template of the given Bind. The
+// credentials are returned unchanged if the Bind has no template.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func applyCredentialTemplate(credentials map[string]interface{}, bind *model.Bind, svcInstance *model.ServiceInstance,
+	logData *log.Data) (map[string]interface{}, error) {
+	if bind.CredentialTemplate == "" {
+		return credentials, nil
+	}
+	logData.Add(LogKeyCredentialTemplate, bind.CredentialTemplate)
+	tmpl := credentialTemplates[bind.CredentialTemplate]
+	if tmpl == nil {
+		log.Error("credential template is not configured", nil, logData)
+		return nil, &mapBrokerError.ErrorUnableToGenerateCredentials{}
+	}
+	keys := CredentialKeys{
+		ApplicationName: svcInstance.ApplicationName,
+		ConsumerKey:     svcInstance.ConsumerKey,
+		ConsumerSecret:  svcInstance.ConsumerSecret,
+	}
+	appID := svcInstance.ApplicationID
+	if credentialModeOf(bind) == CredentialModeBinding {
+		keys = CredentialKeys{
+			ApplicationName: bind.ApplicationName,
+			ConsumerKey:     bind.ConsumerKey,
+			ConsumerSecret:  bind.ConsumerSecret,
+		}
+		appID = bind.ApplicationID
+	}
+	subscriptions, err := getSubscriptionsListForAppID(appID, logData)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, CredentialTemplateData{
+		Instance:      svcInstance,
+		Bind:          bind,
+		Keys:          keys,
+		Subscriptions: subscriptions,
+		Credentials:   credentials,
+	})
+	if err != nil {
+		log.Error("unable to execute the credential template", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToGenerateCredentials{}
+	}
+	var rendered map[string]interface{}
+	err = json.Unmarshal(out.Bytes(), &rendered)
+	if err != nil {
+		log.Error("credential template didn't render a JSON object", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToGenerateCredentials{}
+	}
+	return rendered, nil
+}
+
+// templateJSON encodes the given value as JSON so that templates can safely embed strings and objects.
+func templateJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
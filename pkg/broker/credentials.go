@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+	"github.com/wso2/openservicebroker-apim/pkg/token"
+	"github.com/wso2/openservicebroker-apim/pkg/utils"
+)
+
+const (
+	// CredentialsVersion1 contains the Application name and keys.
+	CredentialsVersion1 = 1
+	// CredentialsVersion2 adds the token endpoint, the gateway URLs of the subscribed APIs and optionally an access
+	// token to the version 1 credentials.
+	CredentialsVersion2 = 2
+
+	CredentialKeyVersion         = "CredentialsVersion"
+	CredentialKeyTokenEndpoint   = "TokenEndpoint"
+	CredentialKeyAPIs            = "APIs"
+	CredentialKeyAccessToken     = "AccessToken"
+	CredentialKeyTokenExpiresIn  = "AccessTokenExpiresIn"
+	ErrMsgUnableToGenCredentials = "unable to generate the bind credentials"
+)
+
+var (
+	defaultCredentialsVersion = CredentialsVersion1
+	credentialsTokenEndpoint  string
+)
+
+// CredentialAPI represents a subscribed API in the version 2 credentials.
+type CredentialAPI struct {
+	Name        string                 `json:"Name"`
+	Version     string                 `json:"Version"`
+	Context     string                 `json:"Context"`
+	GatewayURLs []CredentialGatewayURL `json:"GatewayURLs"`
+}
+
+// CredentialGatewayURL represents the gateway URLs of an API in a gateway environment in the version 2 credentials.
+type CredentialGatewayURL struct {
+	Environment string `json:"Environment"`
+	HTTP        string `json:"HTTP,omitempty"`
+	HTTPS       string `json:"HTTPS,omitempty"`
+}
+
+// initCredentials sets the default credentials version and the token endpoint returned in the credentials. The token
+// endpoint defaults to the one used by the broker. If there is an error it will cause a panic.
+func initCredentials(conf *config.Broker) {
+	defaultCredentialsVersion = conf.Bind.CredentialsVersion
+	if !isValidCredentialsVersion(defaultCredentialsVersion) {
+		log.HandleErrorAndExit("invalid bind.credentialsVersion", nil)
+	}
+	credentialsTokenEndpoint = conf.Bind.TokenEndpoint
+	if credentialsTokenEndpoint == "" {
+		endpoint, err := utils.ConstructURL(conf.APIM.TokenEndpoint, token.Context)
+		if err != nil {
+			log.HandleErrorAndExit("unable to construct the token endpoint", err)
+		}
+		credentialsTokenEndpoint = endpoint
+	}
+}
+
+func isValidCredentialsVersion(version int) bool {
+	return version == CredentialsVersion1 || version == CredentialsVersion2
+}
+
+// credentialsVersionOf returns the credentials version of the given Bind. Binds created before the credentials were
+// versioned use the version 1.
+func credentialsVersionOf(bind *model.Bind) int {
+	if bind.CredentialsVersion == 0 {
+		return CredentialsVersion1
+	}
+	return bind.CredentialsVersion
+}
+
+// bindCredentials returns the credentials of the given Bind in its credentials version, rendered with its credential
+// template if it has one.
+// Returns the credentials and an error type mapped to apiresponses.FailureResponse if encountered.
+func bindCredentials(bind *model.Bind, svcInstance *model.ServiceInstance, logData *log.Data) (map[string]interface{}, error) {
+	credentials, err := versionedCredentials(bind, svcInstance, logData)
+	if err != nil {
+		return nil, err
+	}
+	return applyCredentialTemplate(credentials, bind, svcInstance, logData)
+}
+
+// versionedCredentials returns the credentials of the given Bind in its credentials version. The version 2
+// credentials are generated from the API details in API-M, and contain a new access token if it was requested by the
+// bind parameters.
+// Returns the credentials and an error type mapped to apiresponses.FailureResponse if encountered.
+func versionedCredentials(bind *model.Bind, svcInstance *model.ServiceInstance, logData *log.Data) (map[string]interface{}, error) {
+	credentials := bindCredentialsMap(bind, svcInstance)
+	if credentialsVersionOf(bind) < CredentialsVersion2 {
+		return credentials, nil
+	}
+	apis, err := credentialAPIs(svcInstance.ApplicationID, logData)
+	if err != nil {
+		return nil, err
+	}
+	credentials[CredentialKeyVersion] = CredentialsVersion2
+	credentials[CredentialKeyTokenEndpoint] = credentialsTokenEndpoint
+	credentials[CredentialKeyAPIs] = apis
+
+	var params BindParams
+	if bind.Parameters != "" {
+		// the parameters are validated when the bind is created
+		_ = json.Unmarshal([]byte(bind.Parameters), &params)
+	}
+	if !params.AccessToken {
+		return credentials, nil
+	}
+	consumerKey, consumerSecret, keyParams := svcInstance.ConsumerKey, svcInstance.ConsumerSecret, svcInstance.KeyParameters
+	if credentialModeOf(bind) == CredentialModeBinding {
+		consumerKey, consumerSecret, keyParams = bind.ConsumerKey, bind.ConsumerSecret, bind.KeyParameters
+	}
+	var scopes []string
+	if params := unmarshalKeyParams(keyParams); params != nil {
+		scopes = params.Scopes
+	}
+	resp, err := token.ClientCredentialsToken(credentialsTokenEndpoint, consumerKey, consumerSecret, scopes)
+	if err != nil {
+		log.Error("unable to generate the access token", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToGenerateCredentials{}
+	}
+	credentials[CredentialKeyAccessToken] = resp.AccessToken
+	credentials[CredentialKeyTokenExpiresIn] = resp.ExpiresIn
+	return credentials, nil
+}
+
+// credentialAPIs returns the context and the gateway URLs of the APIs subscribed by the given Application.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func credentialAPIs(appID string, logData *log.Data) ([]CredentialAPI, error) {
+	subscriptions, err := getSubscriptionsListForAppID(appID, logData)
+	if err != nil {
+		return nil, err
+	}
+	backfillSubscriptionAPIIDs(subscriptions, logData)
+	apis := []CredentialAPI{}
+	for _, sub := range subscriptions {
+		api, err := apim.GetStoreAPI(sub.APIID)
+		if err != nil {
+			log.Error("unable to get the API "+sub.APIID, err, logData)
+			return nil, &mapBrokerError.ErrorUnableToGenerateCredentials{}
+		}
+		credentialAPI := CredentialAPI{
+			Name:        api.Name,
+			Version:     api.Version,
+			Context:     api.Context,
+			GatewayURLs: []CredentialGatewayURL{},
+		}
+		for _, endpoint := range api.EndpointURLs {
+			credentialAPI.GatewayURLs = append(credentialAPI.GatewayURLs, CredentialGatewayURL{
+				Environment: endpoint.EnvironmentName,
+				HTTP:        endpoint.URLs.HTTP,
+				HTTPS:       endpoint.URLs.HTTPS,
+			})
+		}
+		apis = append(apis, credentialAPI)
+	}
+	return apis, nil
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"time"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+	"github.com/wso2/openservicebroker-apim/pkg/utils"
+)
+
+const LogKeyVersionSelector = "version-selector"
+
+// validateVersionSelector returns an error type mapped to apiresponses.FailureResponse if the version selector of the
+// given API parameter is invalid or if "follow" is requested for an API without a version selector.
+func validateVersionSelector(api API) error {
+	if !utils.IsVersionSelector(api.Version) {
+		if api.Follow {
+			return &mapBrokerError.ErrorInvalidVersionSelector{}
+		}
+		return nil
+	}
+	if api.ID != "" || utils.ValidateVersionSelector(api.Version) != nil {
+		return &mapBrokerError.ErrorInvalidVersionSelector{}
+	}
+	return nil
+}
+
+// resolveVersionSelector returns the given API parameter resolved to the highest version of the API which matches its
+// version selector.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func resolveVersionSelector(api API, logData *log.Data) (API, error) {
+	logData.Add(LogKeyVersionSelector, api.Version)
+	query := apiSearchQuery(API{Context: api.Context, Name: api.Name, Provider: api.Provider})
+	apiInfoList, err := apim.SearchAPIs(query)
+	if err != nil {
+		log.Error("unable to search the APIs with the query "+query, err, logData)
+		return api, &mapBrokerError.ErrorUnableToSearchAPIs{}
+	}
+	var versions []string
+	candidates := make(map[string][]apim.APISearchInfo)
+	for _, apiInfo := range apiInfoList {
+		if (api.Name != "" && apiInfo.Name != api.Name) || (api.Provider != "" && apiInfo.Provider != api.Provider) {
+			continue
+		}
+		versions = append(versions, apiInfo.Version)
+		candidates[apiInfo.Version] = append(candidates[apiInfo.Version], apiInfo)
+	}
+	version, err := utils.LatestMatchingVersion(api.Version, versions)
+	if err != nil {
+		log.Error("unable to select the API version for the query "+query, err, logData)
+		return api, &mapBrokerError.ErrorNoMatchingAPIVersion{Selector: api.Version}
+	}
+	if len(candidates[version]) > 1 {
+		log.Error("more than one API matches the query "+query+" with the version "+version, nil, logData)
+		return api, &mapBrokerError.ErrorUnableToSearchAPIs{}
+	}
+	apiInfo := candidates[version][0]
+	api.VersionSelector = api.Version
+	api.ID = apiInfo.ID
+	api.Name = apiInfo.Name
+	api.Version = apiInfo.Version
+	api.Context = apiInfo.Context
+	api.Provider = apiInfo.Provider
+	return api, nil
+}
+
+// setVersionSelectors sets the version selector and the follow mode of the given resolved APIs to their subscriptions.
+func setVersionSelectors(subscriptions []model.Subscription, apis []API) {
+	for i := range subscriptions {
+		for _, api := range apis {
+			if api.ID == subscriptions[i].APIID {
+				subscriptions[i].VersionSelector = api.VersionSelector
+				subscriptions[i].Follow = api.Follow
+			}
+		}
+	}
+}
+
+// updateSubscriptionSelectors stores the version selector and the follow mode of the given resolved APIs to the
+// subscriptions of the given Application.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func updateSubscriptionSelectors(applicationID string, apis []API, logData *log.Data) error {
+	for _, api := range apis {
+		sub, err := getSubscriptionForAppAndAPI(applicationID, api, logData)
+		if err != nil {
+			return err
+		}
+		if sub.VersionSelector == api.VersionSelector && sub.Follow == api.Follow {
+			continue
+		}
+		sub.VersionSelector = api.VersionSelector
+		sub.Follow = api.Follow
+		err = db.Update(sub)
+		if err != nil {
+			log.Error("unable to update the subscription in the database", err, logData)
+			return &mapBrokerError.ErrorUnableToUpdateSubscription{}
+		}
+	}
+	return nil
+}
+
+// initAPIFollower starts checking periodically for newer versions of the APIs subscribed with "follow".
+func initAPIFollower(conf *config.Instance) {
+	if conf.FollowInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(conf.FollowInterval) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			followAPIs()
+		}
+	}()
+}
+
+// followAPIs moves the subscriptions of the service instances subscribed with "follow" to the newest matching API
+// versions.
+func followAPIs() {
+	var subscriptions []model.Subscription
+	_, err := db.RetrieveList(&model.Subscription{Follow: true}, &subscriptions)
+	if err != nil {
+		log.Error("unable to retrieve the followed subscriptions", err, nil)
+		return
+	}
+	followed := make(map[string]bool)
+	for _, sub := range subscriptions {
+		if !followed[sub.SVCInstanceID] {
+			followed[sub.SVCInstanceID] = true
+			followServiceInstance(sub.SVCInstanceID)
+		}
+	}
+}
+
+// followServiceInstance starts an update operation for the given service instance if a newer version of any of its
+// followed APIs is available. Instances with an operation in progress or locked are skipped until the next check.
+func followServiceInstance(svcInstanceID string) {
+	logData := log.NewData().Add(LogKeyInstanceID, svcInstanceID)
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil || op != nil {
+		return
+	}
+	lock, err := lockInstance(svcInstanceID, model.OperationUpdate, logData)
+	if err != nil {
+		return
+	}
+	defer lock.release()
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil || svcInstance == nil {
+		return
+	}
+	existingAPIs, err := getExistingAPIsForAppID(svcInstance.ApplicationID, logData)
+	if err != nil {
+		return
+	}
+	var requestedAPIs []API
+	changed := false
+	for _, api := range existingAPIs {
+		if api.Follow && api.VersionSelector != "" {
+			selected, err := resolveVersionSelector(API{
+				Name:     api.Name,
+				Provider: api.Provider,
+				Version:  api.VersionSelector,
+				Tier:     api.Tier,
+				Follow:   true,
+			}, logData)
+			if err != nil {
+				return
+			}
+			if !isSameAPI(selected, api) {
+				changed = true
+			}
+			api = selected
+		}
+		requestedAPIs = append(requestedAPIs, api)
+	}
+	if !changed {
+		return
+	}
+	log.Debug("newer API versions are available", logData)
+	_, err = startOperation(svcInstanceID, model.OperationUpdate, func() error {
+		return updateServiceInstance(svcInstance, "", ServiceParams{APIs: requestedAPIs}, logData)
+	}, logData)
+	if err != nil {
+		log.Error("unable to start following the API versions", err, logData)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+)
+
+const (
+	KeyTypeProduction          = "PRODUCTION"
+	KeyTypeSandbox             = "SANDBOX"
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeImplicit          = "implicit"
+)
+
+// supportedGrantTypes are the grant types which can be requested for the generated keys.
+var supportedGrantTypes = map[string]bool{
+	"client_credentials":       true,
+	"password":                 true,
+	"refresh_token":            true,
+	GrantTypeAuthorizationCode: true,
+	GrantTypeImplicit:          true,
+	"urn:ietf:params:oauth:grant-type:saml2-bearer": true,
+	"urn:ietf:params:oauth:grant-type:jwt-bearer":   true,
+	"iwa:ntlm": true,
+}
+
+// KeyParams represents the options of the keys generated for an Application. The broker defaults are used for the
+// options which are not given.
+type KeyParams struct {
+	KeyType string `json:"keyType,omitempty"`
+	// GrantTypes are the grant types supported by the keys
+	GrantTypes  []string `json:"grantTypes,omitempty" hash:"set"`
+	CallbackURL string   `json:"callbackUrl,omitempty"`
+	// Scopes are the scopes allowed for the access tokens
+	Scopes []string `json:"scopes,omitempty" hash:"set"`
+	// ValidityTime is the validity of the access tokens in seconds
+	ValidityTime int64 `json:"validityTime,omitempty"`
+}
+
+// validateKeyParams validates the given key parameters against the schema of the plan. A nil parameter is valid.
+// Returns an error type mapped to apiresponses.FailureResponse if the parameters are invalid.
+func validateKeyParams(params *KeyParams, logData *log.Data) error {
+	if params == nil {
+		return nil
+	}
+	invalid := func(reason string) error {
+		log.Error("invalid key parameters: "+reason, nil, logData)
+		return &mapBrokerError.ErrorInvalidKeyParameters{Reason: reason}
+	}
+	if params.KeyType != "" && params.KeyType != KeyTypeProduction && params.KeyType != KeyTypeSandbox {
+		return invalid("keyType must be " + KeyTypeProduction + " or " + KeyTypeSandbox)
+	}
+	callbackRequired := false
+	for _, grantType := range params.GrantTypes {
+		if !supportedGrantTypes[grantType] {
+			return invalid("unsupported grant type " + grantType)
+		}
+		if grantType == GrantTypeAuthorizationCode || grantType == GrantTypeImplicit {
+			callbackRequired = true
+		}
+	}
+	if callbackRequired && params.CallbackURL == "" {
+		return invalid("callbackUrl is required for the " + GrantTypeAuthorizationCode + " and " +
+			GrantTypeImplicit + " grant types")
+	}
+	if params.CallbackURL != "" {
+		u, err := url.Parse(params.CallbackURL)
+		if err != nil || !u.IsAbs() {
+			return invalid("callbackUrl must be an absolute URL")
+		}
+	}
+	for _, scope := range params.Scopes {
+		if scope == "" {
+			return invalid("scopes must not be empty")
+		}
+	}
+	if params.ValidityTime < 0 {
+		return invalid("validityTime must not be negative")
+	}
+	return nil
+}
+
+// keyGenerateRequest returns the key generation request of API-M for the given key parameters.
+func keyGenerateRequest(params *KeyParams) *apim.ApplicationKeyGenerateRequest {
+	req := apim.DefaultApplicationKeyGenerateReq()
+	if params == nil {
+		return req
+	}
+	if params.KeyType != "" {
+		req.KeyType = params.KeyType
+	}
+	if len(params.GrantTypes) != 0 {
+		req.GrantTypesToBeSupported = params.GrantTypes
+	}
+	if params.CallbackURL != "" {
+		req.CallbackURL = params.CallbackURL
+	}
+	if len(params.Scopes) != 0 {
+		req.Scopes = params.Scopes
+	}
+	if params.ValidityTime != 0 {
+		req.ValidityTime = strconv.FormatInt(params.ValidityTime, 10)
+	}
+	return req
+}
+
+// keyTypeOf returns the type of the keys generated with the given parameters.
+func keyTypeOf(params *KeyParams) string {
+	if params == nil || params.KeyType == "" {
+		return KeyTypeProduction
+	}
+	return params.KeyType
+}
+
+// marshalKeyParams returns the given key parameters as stored in the database. Nil parameters are stored empty.
+func marshalKeyParams(params *KeyParams) string {
+	if params == nil {
+		return ""
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// unmarshalKeyParams returns the key parameters stored in the database. Nil is returned for the defaults.
+func unmarshalKeyParams(stored string) *KeyParams {
+	if stored == "" {
+		return nil
+	}
+	var params KeyParams
+	if err := json.Unmarshal([]byte(stored), &params); err != nil {
+		return nil
+	}
+	return &params
+}
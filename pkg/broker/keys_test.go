@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+)
+
+func TestValidateKeyParams(t *testing.T) {
+	tests := []struct {
+		params *KeyParams
+		valid  bool
+	}{
+		{nil, true},
+		{&KeyParams{}, true},
+		{&KeyParams{KeyType: KeyTypeSandbox, GrantTypes: []string{"client_credentials"}, ValidityTime: 60}, true},
+		{&KeyParams{GrantTypes: []string{GrantTypeAuthorizationCode}, CallbackURL: "https://example.com/cb"}, true},
+		{&KeyParams{KeyType: "sandbox"}, false},
+		{&KeyParams{GrantTypes: []string{"magic"}}, false},
+		{&KeyParams{GrantTypes: []string{GrantTypeImplicit}}, false},
+		{&KeyParams{CallbackURL: "/cb"}, false},
+		{&KeyParams{Scopes: []string{"default", ""}}, false},
+		{&KeyParams{ValidityTime: -1}, false},
+	}
+	for _, test := range tests {
+		err := validateKeyParams(test.params, log.NewData())
+		if (err == nil) != test.valid {
+			t.Errorf(ErrMsgTestIncorrectResult, test.valid, err)
+		}
+	}
+}
+
+func TestKeyGenerateRequest(t *testing.T) {
+	defaults := apim.DefaultApplicationKeyGenerateReq()
+	tests := []struct {
+		params   *KeyParams
+		expected apim.ApplicationKeyGenerateRequest
+	}{
+		{nil, *defaults},
+		{&KeyParams{}, *defaults},
+		{
+			&KeyParams{
+				KeyType:      KeyTypeSandbox,
+				GrantTypes:   []string{GrantTypeAuthorizationCode},
+				CallbackURL:  "https://example.com/cb",
+				Scopes:       []string{"read"},
+				ValidityTime: 60,
+			},
+			apim.ApplicationKeyGenerateRequest{
+				KeyType:                 KeyTypeSandbox,
+				ValidityTime:            "60",
+				GrantTypesToBeSupported: []string{GrantTypeAuthorizationCode},
+				CallbackURL:             "https://example.com/cb",
+				Scopes:                  []string{"read"},
+			},
+		},
+	}
+	for _, test := range tests {
+		req := keyGenerateRequest(test.params)
+		if !reflect.DeepEqual(*req, test.expected) {
+			t.Errorf(ErrMsgTestIncorrectResult, test.expected, *req)
+		}
+	}
+}
+
+func TestKeyParamsStorage(t *testing.T) {
+	params := &KeyParams{KeyType: KeyTypeSandbox, Scopes: []string{"read"}}
+	stored := marshalKeyParams(params)
+	if result := unmarshalKeyParams(stored); !reflect.DeepEqual(result, params) {
+		t.Errorf(ErrMsgTestIncorrectResult, params, result)
+	}
+	if stored := marshalKeyParams(nil); stored != "" {
+		t.Errorf(ErrMsgTestIncorrectResult, "", stored)
+	}
+	if result := unmarshalKeyParams(""); result != nil {
+		t.Errorf(ErrMsgTestIncorrectResult, nil, result)
+	}
+	if keyType := keyTypeOf(nil); keyType != KeyTypeProduction {
+		t.Errorf(ErrMsgTestIncorrectResult, KeyTypeProduction, keyType)
+	}
+}
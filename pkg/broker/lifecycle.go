@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"strings"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const LogKeyLifecycle = "lifecycle"
+
+// lifecycleTransitions holds the API-M lifecycle actions which move an API from a state to each allowed next state.
+var lifecycleTransitions = map[string]map[string]string{
+	apim.APIStatusCreated: {
+		apim.APIStatusPublished: apim.LifecycleActionPublish,
+	},
+	apim.APIStatusPublished: {
+		apim.APIStatusCreated:    apim.LifecycleActionDemoteToCreated,
+		apim.APIStatusDeprecated: apim.LifecycleActionDeprecate,
+	},
+	apim.APIStatusDeprecated: {
+		apim.APIStatusPublished: apim.LifecycleActionRepublish,
+		apim.APIStatusRetired:   apim.LifecycleActionRetire,
+	},
+	apim.APIStatusRetired: {},
+}
+
+// lifecycleAction returns the lifecycle action which moves an API from the given state to the requested state.
+// Returns an error type mapped to apiresponses.FailureResponse if the transition is not allowed.
+func lifecycleAction(from, to string) (string, error) {
+	action, ok := lifecycleTransitions[from][to]
+	if !ok {
+		return "", &mapBrokerError.ErrorInvalidLifecycleTransition{
+			From: from,
+			To:   to,
+		}
+	}
+	return action, nil
+}
+
+// requestedLifecycle returns the lifecycle state requested by the given parameters for an API in the given state. The
+// "publish" flag requests the PUBLISHED state if no lifecycle state is given and the API is not published yet.
+// An empty string is returned if no state is requested.
+func requestedLifecycle(params *apim.APIParam, current string) string {
+	if params.Lifecycle != "" {
+		return strings.ToUpper(params.Lifecycle)
+	}
+	if params.Publish && current == apim.APIStatusCreated {
+		return apim.APIStatusPublished
+	}
+	return ""
+}
+
+// validateLifecycleTransition checks whether an API in the given state can be moved to the requested state.
+// Requesting the current state is allowed. Returns an error type mapped to apiresponses.FailureResponse.
+func validateLifecycleTransition(from, to string, logData *log.Data) error {
+	if to == "" || to == from {
+		return nil
+	}
+	_, err := lifecycleAction(from, to)
+	if err != nil {
+		log.Error("invalid lifecycle transition", err, logData)
+		return err
+	}
+	return nil
+}
+
+// changeAPILifecycle moves the API of the given API instance to the requested lifecycle state and records the
+// resulting state in the instance. Returns any error encountered.
+func changeAPILifecycle(apiInstance *model.APIInstance, to string, logData *log.Data) error {
+	if to == "" || to == apiInstance.LifecycleStatus {
+		return nil
+	}
+	logData.Add(LogKeyLifecycle, to)
+	action, err := lifecycleAction(apiInstance.LifecycleStatus, to)
+	if err != nil {
+		log.Error("invalid lifecycle transition", err, logData)
+		return err
+	}
+	log.Debug("change the API lifecycle", logData)
+	err = apim.ChangeAPILifecycle(apiInstance.APIID, action)
+	if err != nil {
+		log.Error("unable to change the API lifecycle", err, logData)
+		return handleAPIMResourceUpdateError(err, apiInstance.APIName)
+	}
+	apiInstance.LifecycleStatus = to
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"testing"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+)
+
+func TestLifecycleAction(t *testing.T) {
+	tests := []struct {
+		from, to string
+		action   string
+	}{
+		{apim.APIStatusCreated, apim.APIStatusPublished, apim.LifecycleActionPublish},
+		{apim.APIStatusPublished, apim.APIStatusCreated, apim.LifecycleActionDemoteToCreated},
+		{apim.APIStatusPublished, apim.APIStatusDeprecated, apim.LifecycleActionDeprecate},
+		{apim.APIStatusDeprecated, apim.APIStatusPublished, apim.LifecycleActionRepublish},
+		{apim.APIStatusDeprecated, apim.APIStatusRetired, apim.LifecycleActionRetire},
+		{apim.APIStatusCreated, apim.APIStatusDeprecated, ""},
+		{apim.APIStatusCreated, apim.APIStatusRetired, ""},
+		{apim.APIStatusPublished, apim.APIStatusRetired, ""},
+		{apim.APIStatusRetired, apim.APIStatusPublished, ""},
+		{apim.APIStatusPublished, "PROTOTYPED", ""},
+		{"UNKNOWN", apim.APIStatusPublished, ""},
+	}
+	for _, test := range tests {
+		action, err := lifecycleAction(test.from, test.to)
+		if action != test.action {
+			t.Errorf(ErrMsgTestIncorrectResult, test.action, action)
+		}
+		if (err == nil) != (test.action != "") {
+			t.Errorf(ErrMsgTestIncorrectResult, test.action != "", err)
+		}
+	}
+}
+
+func TestValidateLifecycleTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		valid    bool
+	}{
+		{apim.APIStatusCreated, "", true},
+		{apim.APIStatusRetired, apim.APIStatusRetired, true},
+		{apim.APIStatusCreated, apim.APIStatusPublished, true},
+		{apim.APIStatusRetired, apim.APIStatusCreated, false},
+		{apim.APIStatusCreated, apim.APIStatusRetired, false},
+	}
+	for _, test := range tests {
+		err := validateLifecycleTransition(test.from, test.to, log.NewData())
+		if (err == nil) != test.valid {
+			t.Errorf(ErrMsgTestIncorrectResult, test.valid, err)
+		}
+	}
+}
+
+func TestRequestedLifecycle(t *testing.T) {
+	tests := []struct {
+		params   apim.APIParam
+		current  string
+		expected string
+	}{
+		{apim.APIParam{Lifecycle: "deprecated"}, apim.APIStatusPublished, apim.APIStatusDeprecated},
+		{apim.APIParam{Publish: true}, apim.APIStatusCreated, apim.APIStatusPublished},
+		{apim.APIParam{Publish: true}, apim.APIStatusDeprecated, ""},
+		{apim.APIParam{}, apim.APIStatusCreated, ""},
+	}
+	for _, test := range tests {
+		result := requestedLifecycle(&test.params, test.current)
+		if result != test.expected {
+			t.Errorf(ErrMsgTestIncorrectResult, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+// LockRenewalsPerTimeout is the number of times a held lock is renewed within the operation timeout.
+const LockRenewalsPerTimeout = 3
+
+var (
+	// lockOwner identifies the locks held by this broker replica.
+	lockOwner  = uuid.New().String()
+	heldLocks  = make(map[string]*instanceLock)
+	locksMutex sync.Mutex
+)
+
+// instanceLock is a lock of a service instance held by this broker replica. A lock taken by a request is handed off
+// to the operation worker if the request starts an asynchronous operation, and released when the operation finishes.
+// The lock is renewed until it is released.
+type instanceLock struct {
+	record      *model.InstanceLock
+	handedOff   bool
+	released    bool
+	logData     *log.Data
+	stopRenewal chan struct{}
+}
+
+// lockInstance locks the given service instance for an operation of the given type. The lock is renewed while it is
+// held and expires after the configured operation timeout once it is not renewed anymore, so that the lock of an
+// interrupted operation does not hold the instance forever.
+// Returns the lock and an error type mapped to apiresponses.FailureResponse if encountered. ErrorOperationInProgress
+// is returned if the instance is locked by another operation.
+func lockInstance(svcInstanceID, opType string, logData *log.Data) (*instanceLock, error) {
+	record := &model.InstanceLock{
+		ID:        svcInstanceID,
+		Owner:     lockOwner,
+		Operation: opType,
+		ExpiresAt: time.Now().Add(operationTimeout),
+	}
+	err := db.Store(record)
+	if err != nil {
+		taken, err := takeOverExpiredLock(record, logData)
+		if err != nil {
+			return nil, err
+		}
+		if !taken {
+			log.Debug("service instance is locked by another operation", logData)
+			return nil, &mapBrokerError.ErrorOperationInProgress{}
+		}
+	}
+	lock := &instanceLock{
+		record:      record,
+		logData:     logData,
+		stopRenewal: make(chan struct{}),
+	}
+	locksMutex.Lock()
+	heldLocks[svcInstanceID] = lock
+	locksMutex.Unlock()
+	go lock.renew()
+	return lock, nil
+}
+
+// renew extends the expiry of the lock periodically until the lock is released, so that the lock of a long running or
+// queued operation does not expire while the operation is still pending.
+func (l *instanceLock) renew() {
+	if operationTimeout <= 0 {
+		return
+	}
+	logData := log.NewData().Add(LogKeyInstanceID, l.record.ID)
+	ticker := time.NewTicker(operationTimeout / LockRenewalsPerTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopRenewal:
+			return
+		case <-ticker.C:
+			renewed, err := db.UpdateByQuery(&model.InstanceLock{},
+				map[string]interface{}{"expires_at": time.Now().Add(operationTimeout)},
+				"id = ? AND owner = ?", l.record.ID, l.record.Owner)
+			if err != nil {
+				log.Error("unable to renew the instance lock", err, logData)
+				continue
+			}
+			if renewed == 0 {
+				log.Error("the instance lock is taken over by another replica", nil, logData)
+				return
+			}
+		}
+	}
+}
+
+// takeOverExpiredLock stores the given lock in place of the existing lock of the instance if it is expired. The
+// operation which held the expired lock was interrupted, so its saga is recovered before the lock is taken over.
+// Returns true if the lock is taken and an error type mapped to apiresponses.FailureResponse if encountered.
+func takeOverExpiredLock(record *model.InstanceLock, logData *log.Data) (bool, error) {
+	existing := &model.InstanceLock{ID: record.ID}
+	exists, err := db.Retrieve(existing)
+	if err != nil {
+		log.Error("unable to retrieve the instance lock", err, logData)
+		return false, &mapBrokerError.ErrorUnableToLockInstance{}
+	}
+	if exists && existing.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	if exists {
+		log.Info("take over the expired lock of the "+existing.Operation+" operation", logData)
+		// only the replica which removes the expired lock may take it over
+		deleted, err := db.DeleteByQuery(&model.InstanceLock{}, "id = ? AND owner = ? AND expires_at < ?",
+			existing.ID, existing.Owner, time.Now())
+		if err != nil {
+			log.Error("unable to delete the expired instance lock", err, logData)
+			return false, &mapBrokerError.ErrorUnableToLockInstance{}
+		}
+		if deleted == 0 {
+			return false, nil
+		}
+	}
+	err = db.Store(record)
+	if err != nil {
+		if exists, _ := db.Retrieve(&model.InstanceLock{ID: record.ID}); exists {
+			return false, nil
+		}
+		log.Error("unable to store the instance lock", err, logData)
+		return false, &mapBrokerError.ErrorUnableToLockInstance{}
+	}
+	if exists && !recoverInstanceSagas(record.ID, logData) {
+		_, err = db.DeleteByQuery(&model.InstanceLock{}, "id = ? AND owner = ?", record.ID, record.Owner)
+		if err != nil {
+			log.Error("unable to release the instance lock", err, logData)
+		}
+		return false, &mapBrokerError.ErrorUnableToLockInstance{}
+	}
+	return true, nil
+}
+
+// release removes the lock unless it is handed off to an operation worker or already released.
+func (l *instanceLock) release() {
+	locksMutex.Lock()
+	if l.handedOff || l.released {
+		locksMutex.Unlock()
+		return
+	}
+	l.released = true
+	close(l.stopRenewal)
+	if heldLocks[l.record.ID] == l {
+		delete(heldLocks, l.record.ID)
+	}
+	locksMutex.Unlock()
+	_, err := db.DeleteByQuery(&model.InstanceLock{}, "id = ? AND owner = ?", l.record.ID, l.record.Owner)
+	if err != nil {
+		log.Error("unable to release the instance lock", err, l.logData)
+	}
+}
+
+// handOffInstanceLock hands off the lock held on the given instance to an operation worker. A nil lock is returned
+// if the instance is not locked by this replica.
+func handOffInstanceLock(svcInstanceID string) *instanceLock {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+	lock := heldLocks[svcInstanceID]
+	if lock != nil {
+		lock.handedOff = true
+	}
+	return lock
+}
+
+// releaseHandedOffLock releases the given lock held by an operation worker.
+func releaseHandedOffLock(lock *instanceLock) {
+	if lock == nil {
+		return
+	}
+	locksMutex.Lock()
+	lock.handedOff = false
+	locksMutex.Unlock()
+	lock.release()
+}
+
+// isInstanceLocked returns true if the given instance is locked by an operation which is not expired.
+func isInstanceLocked(svcInstanceID string, logData *log.Data) (bool, error) {
+	lock := &model.InstanceLock{ID: svcInstanceID}
+	exists, err := db.Retrieve(lock)
+	if err != nil {
+		log.Error("unable to retrieve the instance lock", err, logData)
+		return false, &mapBrokerError.ErrorUnableToLockInstance{}
+	}
+	return exists && lock.ExpiresAt.After(time.Now()), nil
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mitchellh/hashstructure"
+	"github.com/pivotal-cf/brokerapi/domain"
+	"github.com/pivotal-cf/brokerapi/domain/apiresponses"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	LogKeyOperationID       = "operation-id"
+	LogKeyOperationType     = "operation-type"
+	DescOperationInProgress = "%s is in progress"
+	DescOperationSucceeded  = "%s succeeded"
+	DescOperationFailed     = "%s failed: %s"
+	DescOperationTimedOut   = "%s timed out"
+	DescOperationQueueFull  = "%s could not be queued"
+)
+
+var (
+	operationQueue   chan *operationJob
+	operationTimeout time.Duration
+)
+
+// operationJob represents an asynchronous operation waiting to be executed by an operation worker.
+type operationJob struct {
+	operation *model.Operation
+	run       func() error
+	lock      *instanceLock
+	logData   *log.Data
+}
+
+// initOperationWorkers creates the operation queue and starts the workers which execute the asynchronous operations.
+func initOperationWorkers(conf *config.Operation) {
+	operationQueue = make(chan *operationJob, conf.QueueSize)
+	operationTimeout = time.Duration(conf.Timeout) * time.Second
+	for i := 0; i < conf.Workers; i++ {
+		go operationWorker()
+	}
+}
+
+// operationWorker executes the operations in the operation queue.
+func operationWorker() {
+	for job := range operationQueue {
+		executeOperation(job)
+	}
+}
+
+// executeOperation runs the given job and records the outcome of the operation in the database.
+func executeOperation(job *operationJob) {
+	log.Debug("executing the operation", job.logData)
+	op := job.operation
+	defer releaseHandedOffLock(job.lock)
+	err := job.run()
+	if err != nil {
+		log.Error("operation failed", err, job.logData)
+		op.State = model.OperationStateFailed
+		op.Description = fmt.Sprintf(DescOperationFailed, op.Type, err.Error())
+	} else {
+		log.Debug("operation succeeded", job.logData)
+		op.State = model.OperationStateSucceeded
+		op.Description = fmt.Sprintf(DescOperationSucceeded, op.Type)
+	}
+	updateOperationAndLogError(op, job.logData)
+}
+
+// startOperation stores an in progress operation of the given type for the given instance and queues the given
+// function to be executed by an operation worker. The lock held on the instance is released when the operation
+// finishes.
+// Returns the stored operation and an error type mapped to apiresponses.FailureResponse if encountered.
+func startOperation(svcInstanceID, opType string, run func() error, logData *log.Data) (*model.Operation, error) {
+	return startRequestOperation(svcInstanceID, opType, "", run, logData)
+}
+
+// startRequestOperation starts an operation like startOperation and stores the given hash of the request which started
+// it, so that a repeated request can be told apart from a conflicting one while the operation is in progress.
+func startRequestOperation(svcInstanceID, opType, requestHash string, run func() error, logData *log.Data) (*model.Operation, error) {
+	op := &model.Operation{
+		ID:            uuid.New().String(),
+		SVCInstanceID: svcInstanceID,
+		Type:          opType,
+		State:         model.OperationStateInProgress,
+		Description:   fmt.Sprintf(DescOperationInProgress, opType),
+		RequestHash:   requestHash,
+	}
+	logData.Add(LogKeyOperationID, op.ID).
+		Add(LogKeyOperationType, opType)
+	err := db.Store(op)
+	if err != nil {
+		log.Error("unable to store the operation", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToStoreOperation{}
+	}
+	job := &operationJob{operation: op, run: run, lock: handOffInstanceLock(svcInstanceID), logData: logData}
+	select {
+	case operationQueue <- job:
+		return op, nil
+	default:
+		releaseHandedOffLock(job.lock)
+		log.Error("unable to queue the operation", nil, logData)
+		op.State = model.OperationStateFailed
+		op.Description = fmt.Sprintf(DescOperationQueueFull, opType)
+		updateOperationAndLogError(op, logData)
+		return nil, &mapBrokerError.ErrorOperationQueueFull{}
+	}
+}
+
+// retrieveOperation returns the operation with the given ID for the given instance. If the operation ID is empty the
+// latest operation of the instance is returned. A nil operation is returned if no operation is found.
+func retrieveOperation(svcInstanceID, operationID string, logData *log.Data) (*model.Operation, error) {
+	operations, err := retrieveOperationList(&model.Operation{
+		ID:            operationID,
+		SVCInstanceID: svcInstanceID,
+	}, logData)
+	if err != nil {
+		return nil, err
+	}
+	var latest *model.Operation
+	for i := range operations {
+		if latest == nil || operations[i].CreatedAt.After(latest.CreatedAt) {
+			latest = &operations[i]
+		}
+	}
+	return latest, nil
+}
+
+// retrieveInProgressOperation returns the in progress operation of the given instance or nil if there is none.
+// Operations exceeding the configured timeout are marked as failed and ignored.
+func retrieveInProgressOperation(svcInstanceID string, logData *log.Data) (*model.Operation, error) {
+	operations, err := retrieveOperationList(&model.Operation{
+		SVCInstanceID: svcInstanceID,
+		State:         model.OperationStateInProgress,
+	}, logData)
+	if err != nil {
+		return nil, err
+	}
+	for i := range operations {
+		if !expireOperationIfTimedOut(&operations[i], logData) {
+			return &operations[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// generateProvisionRequestHash returns the hash of a provision request of the given plan with the given parameter hash
+// and platform context.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func generateProvisionRequestHash(planID, paramHash string, platform *platformContext, logData *log.Data) (string, error) {
+	generatedHash, err := hashstructure.Hash(struct {
+		PlanID          string
+		ParameterHash   string
+		Platform        string
+		PlatformContext string
+	}{planID, paramHash, platform.platform, platform.identifiersJSON()}, nil)
+	if err != nil {
+		log.Error("unable to generate hash value for the provision request", err, logData)
+		return "", &mapBrokerError.ErrorUnableToGenerateHash{}
+	}
+	return strconv.FormatUint(generatedHash, 10), nil
+}
+
+// provisionInProgress returns the spec of the given in progress operation for a provision request with the given hash.
+// Returns an error type mapped to apiresponses.FailureResponse, ErrorOperationInProgress is returned if the operation
+// is not a provision or it can't be polled, and ErrInstanceAlreadyExists if the operation is started by a provision
+// request with different attributes.
+func provisionInProgress(op *model.Operation, requestHash string, asyncAllowed bool) (domain.ProvisionedServiceSpec, error) {
+	if !asyncAllowed || op.Type != model.OperationProvision {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(&mapBrokerError.ErrorOperationInProgress{})
+	}
+	if op.RequestHash != requestHash {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+	return domain.ProvisionedServiceSpec{
+		IsAsync:       true,
+		OperationData: op.ID,
+	}, nil
+}
+
+func retrieveOperationList(query *model.Operation, logData *log.Data) ([]model.Operation, error) {
+	var operations []model.Operation
+	_, err := db.RetrieveList(query, &operations)
+	if err != nil {
+		log.Error("unable to retrieve operations", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToRetrieveOperation{}
+	}
+	return operations, nil
+}
+
+// expireOperationIfTimedOut marks the given in progress operation as failed if it has exceeded the configured timeout.
+// Timed out operations are usually the ones interrupted by a broker restart. An operation whose instance lock is still
+// renewed is pending in a worker or the queue, so it is not timed out however long it takes.
+// Returns true if the operation is timed out.
+func expireOperationIfTimedOut(op *model.Operation, logData *log.Data) bool {
+	if op.State != model.OperationStateInProgress || time.Since(op.UpdatedAt) < operationTimeout {
+		return false
+	}
+	locked, err := isInstanceLocked(op.SVCInstanceID, logData)
+	if err != nil || locked {
+		return false
+	}
+	op.State = model.OperationStateFailed
+	op.Description = fmt.Sprintf(DescOperationTimedOut, op.Type)
+	updateOperationAndLogError(op, logData)
+	return true
+}
+
+func updateOperationAndLogError(op *model.Operation, logData *log.Data) {
+	err := db.Update(op)
+	if err != nil {
+		log.Error("unable to update the operation", err, logData)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"testing"
+
+	"github.com/pivotal-cf/brokerapi/domain/apiresponses"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+func TestGenerateProvisionRequestHash(t *testing.T) {
+	cf := &platformContext{platform: PlatformCloudFoundry, identifiers: map[string]string{ContextKeySpaceID: "space"}}
+	otherSpace := &platformContext{platform: PlatformCloudFoundry, identifiers: map[string]string{ContextKeySpaceID: "other"}}
+	hash, err := generateProvisionRequestHash(ApplicationPlanID, "1", cf, log.NewData())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		planID    string
+		paramHash string
+		platform  *platformContext
+		same      bool
+	}{
+		{ApplicationPlanID, "1", cf, true},
+		{"gold-plan", "1", cf, false},
+		{ApplicationPlanID, "2", cf, false},
+		{ApplicationPlanID, "1", otherSpace, false},
+	}
+	for _, test := range tests {
+		h, err := generateProvisionRequestHash(test.planID, test.paramHash, test.platform, log.NewData())
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if (h == hash) != test.same {
+			t.Errorf(ErrMsgTestIncorrectResult, test.same, h == hash)
+		}
+	}
+}
+
+func TestProvisionInProgress(t *testing.T) {
+	tests := []struct {
+		opType       string
+		requestHash  string
+		asyncAllowed bool
+		async        bool
+		conflict     bool
+	}{
+		{model.OperationProvision, "1", true, true, false},
+		{model.OperationProvision, "2", true, false, true},
+		{model.OperationProvision, "1", false, false, false},
+		{model.OperationUpdate, "1", true, false, false},
+	}
+	for _, test := range tests {
+		op := &model.Operation{ID: "op", Type: test.opType, RequestHash: "1"}
+		spec, err := provisionInProgress(op, test.requestHash, test.asyncAllowed)
+		if spec.IsAsync != test.async {
+			t.Errorf(ErrMsgTestIncorrectResult, test.async, spec.IsAsync)
+		}
+		if (err == apiresponses.ErrInstanceAlreadyExists) != test.conflict {
+			t.Errorf(ErrMsgTestIncorrectResult, test.conflict, err)
+		}
+		if test.async && spec.OperationData != op.ID {
+			t.Errorf(ErrMsgTestIncorrectResult, op.ID, spec.OperationData)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"strings"
+	"time"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+)
+
+// orphanCandidates holds the IDs of the Applications found without a service instance or bind in the last sweep. An
+// Application is deleted only if it is found again in the next sweep, so that Applications of provisions which are
+// still running are not deleted.
+var orphanCandidates = make(map[string]bool)
+
+// deleteOrphanApplication deletes the Application created for the given service instance if it is left in API-M
+// without a service instance, e.g. when storing the instance failed after the Application was created.
+// Returns true if an Application is deleted and an error type mapped to apiresponses.FailureResponse if encountered.
+func deleteOrphanApplication(svcInstanceID string, logData *log.Data) (bool, error) {
+	appName := generateApplicationName(svcInstanceID)
+	apps, err := apim.SearchApplications(appName)
+	if err != nil {
+		log.Error("unable to search the Application "+appName, err, logData)
+		return false, &mapBrokerError.ErrorUnableToSearchApplications{}
+	}
+	deleted := false
+	for _, app := range apps {
+		if app.Name != appName {
+			continue
+		}
+		managed, err := isManagedApplication(app.ApplicationID, logData)
+		if err != nil {
+			return false, err
+		}
+		if managed {
+			continue
+		}
+		logData.Add(LogKeyAppID, app.ApplicationID)
+		log.Debug("delete the orphan application", logData)
+		err = apim.DeleteApplication(app.ApplicationID)
+		if err != nil {
+			log.Error("unable to delete the orphan Application", err, logData)
+			return false, &mapBrokerError.ErrorUnableToDeleteAPIMResource{}
+		}
+		deleted = true
+	}
+	return deleted, nil
+}
+
+// initOrphanSweeper starts deleting periodically the broker Applications left in API-M without a service instance or
+// bind.
+func initOrphanSweeper(conf *config.Instance) {
+	if conf.OrphanSweepInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(conf.OrphanSweepInterval) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			sweepOrphanApplications()
+		}
+	}()
+}
+
+// sweepOrphanApplications deletes the broker Applications which are found without a service instance or bind in two
+// consecutive sweeps.
+func sweepOrphanApplications() {
+	apps, err := apim.SearchApplications(ApplicationPrefix)
+	if err != nil {
+		log.Error("unable to search the broker Applications", err, nil)
+		return
+	}
+	candidates := make(map[string]bool)
+	for _, app := range apps {
+		if !strings.HasPrefix(app.Name, ApplicationPrefix) {
+			continue
+		}
+		logData := log.NewData().
+			Add(LogKeyAppID, app.ApplicationID).
+			Add(LogKeyApplicationName, app.Name)
+		managed, err := isManagedApplication(app.ApplicationID, logData)
+		if err != nil || managed {
+			continue
+		}
+		op, err := retrieveInProgressOperation(strings.TrimPrefix(app.Name, ApplicationPrefix), logData)
+		if err != nil || op != nil {
+			continue
+		}
+		locked, err := isInstanceLocked(strings.TrimPrefix(app.Name, ApplicationPrefix), logData)
+		if err != nil || locked {
+			continue
+		}
+		if !orphanCandidates[app.ApplicationID] {
+			candidates[app.ApplicationID] = true
+			continue
+		}
+		log.Debug("delete the orphan application", logData)
+		err = apim.DeleteApplication(app.ApplicationID)
+		if err != nil {
+			log.Error("unable to delete the orphan Application", err, logData)
+			candidates[app.ApplicationID] = true
+		}
+	}
+	orphanCandidates = candidates
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	DefaultApplicationThrottlingPolicy = "Unlimited"
+	ApplicationTokenType               = "OAUTH"
+	LogKeyThrottlingPolicy             = "throttling-policy"
+	ErrMsgInvalidPlanConf              = "invalid plan configuration"
+	DynamicPlanDescription             = "Creates an Application with the %s throttling policy"
+)
+
+// applicationPlan represents a catalog plan which creates an Application with the given throttling policy.
+// Dynamic plans are built from the throttling policies available in API-M. A dynamic plan whose policy is no longer
+// available is retired, it is removed from the catalog but kept so that existing instances can still be resolved.
+type applicationPlan struct {
+	id               string
+	name             string
+	displayName      string
+	description      string
+	throttlingPolicy string
+	// credentialTemplate is the default credential template of the binds, empty for bind.credentialTemplate
+	credentialTemplate string
+	dynamic            bool
+	retired            bool
+}
+
+var (
+	applicationPlans []applicationPlan
+	plansLock        sync.RWMutex
+)
+
+// initApplicationPlans initializes the Application plans with the default "app" plan and the configured plans.
+// The credential templates must be initialized first. If a configured plan is invalid the process exits.
+func initApplicationPlans(conf *config.Catalog) {
+	applicationPlans = []applicationPlan{{
+		id:               ApplicationPlanID,
+		name:             ApplicationPlanName,
+		description:      ApplicationPlanDescription,
+		throttlingPolicy: DefaultApplicationThrottlingPolicy,
+	}}
+	for _, p := range conf.Plans {
+		if p.ID == "" || p.Name == "" || p.ThrottlingPolicy == "" {
+			log.HandleErrorAndExit(ErrMsgInvalidPlanConf,
+				errors.New("id, name and throttlingPolicy are required for the plan: "+p.Name))
+		}
+		if getApplicationPlan(p.ID) != nil {
+			log.HandleErrorAndExit(ErrMsgInvalidPlanConf, errors.New("duplicate plan id: "+p.ID))
+		}
+		if !isCredentialTemplate(p.CredentialTemplate) {
+			log.HandleErrorAndExit(ErrMsgInvalidPlanConf,
+				errors.New("unknown credential template "+p.CredentialTemplate+" for the plan: "+p.Name))
+		}
+		applicationPlans = append(applicationPlans, applicationPlan{
+			id:                 p.ID,
+			name:               p.Name,
+			description:        p.Description,
+			throttlingPolicy:   p.ThrottlingPolicy,
+			credentialTemplate: p.CredentialTemplate,
+		})
+	}
+}
+
+// getApplicationPlan returns a copy of the Application plan for the given plan ID or nil if there is no such plan.
+func getApplicationPlan(planID string) *applicationPlan {
+	plansLock.RLock()
+	defer plansLock.RUnlock()
+	for _, plan := range applicationPlans {
+		if plan.id == planID {
+			return &plan
+		}
+	}
+	return nil
+}
+
+// instanceServiceID returns the service ID of the given service instance. Instances created before the service ID was
+// stored have an empty service ID and belong to the broker service.
+func instanceServiceID(svcInstance *model.ServiceInstance) string {
+	if svcInstance.ServiceID == "" {
+		return ServiceID
+	}
+	return svcInstance.ServiceID
+}
+
+// instancePlanID returns the plan ID of the given service instance. Instances created before the plan ID was stored
+// have an empty plan ID and belong to the default Application plan.
+func instancePlanID(svcInstance *model.ServiceInstance) string {
+	if svcInstance.PlanID == "" {
+		return ApplicationPlanID
+	}
+	return svcInstance.PlanID
+}
+
+// activeApplicationPlans returns the Application plans which are offered in the catalog.
+func activeApplicationPlans() []applicationPlan {
+	plansLock.RLock()
+	defer plansLock.RUnlock()
+	var plans []applicationPlan
+	for _, plan := range applicationPlans {
+		if !plan.retired {
+			plans = append(plans, plan)
+		}
+	}
+	return plans
+}
+
+// dynamicPlanID returns the plan ID of the given throttling policy. The ID is derived from the policy name so that all
+// the broker replicas offer the same plans.
+func dynamicPlanID(throttlingPolicy string) string {
+	return uuid.NewSHA1(uuid.MustParse(ServiceID), []byte(throttlingPolicy)).String()
+}
+
+// setDynamicPlans replaces the dynamic plans with plans for the given throttling policies. Policies already used by a
+// configured plan are skipped. Dynamic plans of the policies which are not given anymore are retired.
+func setDynamicPlans(policies []apim.ThrottlingPolicyInfo) {
+	plansLock.Lock()
+	defer plansLock.Unlock()
+	var plans []applicationPlan
+	usedNames := make(map[string]bool)
+	usedPolicies := make(map[string]bool)
+	for _, plan := range applicationPlans {
+		if !plan.dynamic {
+			plans = append(plans, plan)
+			usedNames[plan.name] = true
+			usedPolicies[plan.throttlingPolicy] = true
+		}
+	}
+	for _, policy := range policies {
+		if policy.Name == "" || usedNames[policy.Name] || usedPolicies[policy.Name] {
+			continue
+		}
+		description := policy.Description
+		if description == "" {
+			description = fmt.Sprintf(DynamicPlanDescription, policy.Name)
+		}
+		plans = append(plans, applicationPlan{
+			id:               dynamicPlanID(policy.Name),
+			name:             policy.Name,
+			displayName:      policy.DisplayName,
+			description:      description,
+			throttlingPolicy: policy.Name,
+			dynamic:          true,
+		})
+		usedNames[policy.Name] = true
+		usedPolicies[policy.Name] = true
+	}
+	for _, plan := range applicationPlans {
+		if plan.dynamic && !usedPolicies[plan.throttlingPolicy] {
+			plan.retired = true
+			plans = append(plans, plan)
+		}
+	}
+	applicationPlans = plans
+}
+
+// throttlingPolicyForPlan returns the Application throttling policy of the given plan.
+// Returns an error type mapped to apiresponses.FailureResponse if the plan is unknown.
+func throttlingPolicyForPlan(planID string, logData *log.Data) (string, error) {
+	plan := getApplicationPlan(planID)
+	if plan == nil {
+		log.Error(ErrMsgInvalidPlanID, ErrInvalidSVCPlan, logData)
+		return "", &mapBrokerError.ErrorInvalidPlan{}
+	}
+	return plan.throttlingPolicy, nil
+}
+
+// instanceThrottlingPolicy returns the Application throttling policy of the given service instance. Instances created
+// before the policy was stored use the policy of their plan.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func instanceThrottlingPolicy(svcInstance *model.ServiceInstance, logData *log.Data) (string, error) {
+	if svcInstance.ThrottlingPolicy != "" {
+		return svcInstance.ThrottlingPolicy, nil
+	}
+	return throttlingPolicyForPlan(instancePlanID(svcInstance), logData)
+}
+
+// applicationReq returns the request body to create or update the given Application with the given throttling policy.
+func applicationReq(appName, throttlingPolicy string) *apim.ApplicationCreateReq {
+	return &apim.ApplicationCreateReq{
+		Name:             appName,
+		ThrottlingPolicy: throttlingPolicy,
+		Description:      "Application " + appName + " created by WSO2 APIM Service Broker",
+		TokenType:        ApplicationTokenType,
+	}
+}
+
+// updateApplicationThrottlingPolicy moves the given Application to the given throttling policy.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func updateApplicationThrottlingPolicy(appID, appName, throttlingPolicy string, logData *log.Data) error {
+	logData.Add(LogKeyThrottlingPolicy, throttlingPolicy)
+	err := apim.UpdateApplication(appID, applicationReq(appName, throttlingPolicy))
+	if err != nil {
+		log.Error("unable to update the Application throttling policy", err, logData)
+		return handleAPIMResourceUpdateError(err, appName)
+	}
+	return nil
+}
+
+// validatePlan returns an error type mapped to apiresponses.FailureResponse if the given plan is not an
+// Application plan offered in the catalog.
+func validatePlan(planID string, logData *log.Data) error {
+	plan := getApplicationPlan(planID)
+	if plan == nil || plan.retired {
+		log.Error(ErrMsgInvalidPlanID, ErrInvalidSVCPlan, logData)
+		return &mapBrokerError.ErrorInvalidPlan{}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"testing"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const ErrMsgTestIncorrectResult = "expected value: %v but then returned value: %v"
+
+func setUpApplicationPlans() {
+	initApplicationPlans(&config.Catalog{
+		Plans: []config.Plan{{ID: "gold-plan", Name: "gold", ThrottlingPolicy: "Gold"}},
+	})
+}
+
+func TestSetDynamicPlans(t *testing.T) {
+	setUpApplicationPlans()
+	setDynamicPlans([]apim.ThrottlingPolicyInfo{{Name: "Gold"}, {Name: "Silver"}, {Name: "Bronze"}, {Name: "app"}})
+	setDynamicPlans([]apim.ThrottlingPolicyInfo{{Name: "Silver"}})
+
+	tests := []struct {
+		planID  string
+		exists  bool
+		dynamic bool
+		retired bool
+	}{
+		{ApplicationPlanID, true, false, false},
+		{"gold-plan", true, false, false},
+		{dynamicPlanID("Gold"), false, false, false},
+		{dynamicPlanID("app"), false, false, false},
+		{dynamicPlanID("Silver"), true, true, false},
+		{dynamicPlanID("Bronze"), true, true, true},
+	}
+	for _, test := range tests {
+		plan := getApplicationPlan(test.planID)
+		if (plan != nil) != test.exists {
+			t.Errorf(ErrMsgTestIncorrectResult, test.exists, plan != nil)
+			continue
+		}
+		if plan == nil {
+			continue
+		}
+		if plan.dynamic != test.dynamic {
+			t.Errorf(ErrMsgTestIncorrectResult, test.dynamic, plan.dynamic)
+		}
+		if plan.retired != test.retired {
+			t.Errorf(ErrMsgTestIncorrectResult, test.retired, plan.retired)
+		}
+	}
+	if n := len(activeApplicationPlans()); n != 3 {
+		t.Errorf(ErrMsgTestIncorrectResult, 3, n)
+	}
+
+	setDynamicPlans([]apim.ThrottlingPolicyInfo{{Name: "Silver"}, {Name: "Bronze"}})
+	if plan := getApplicationPlan(dynamicPlanID("Bronze")); plan == nil || plan.retired {
+		t.Errorf(ErrMsgTestIncorrectResult, "active Bronze plan", plan)
+	}
+}
+
+func TestValidatePlan(t *testing.T) {
+	setUpApplicationPlans()
+	setDynamicPlans([]apim.ThrottlingPolicyInfo{{Name: "Bronze"}})
+	setDynamicPlans(nil)
+
+	tests := map[string]bool{
+		ApplicationPlanID:       true,
+		"gold-plan":             true,
+		dynamicPlanID("Bronze"): false,
+		"unknown-plan":          false,
+	}
+	for planID, valid := range tests {
+		err := validatePlan(planID, log.NewData())
+		if (err == nil) != valid {
+			t.Errorf(ErrMsgTestIncorrectResult, valid, err)
+		}
+	}
+}
+
+func TestThrottlingPolicyForPlan(t *testing.T) {
+	setUpApplicationPlans()
+	setDynamicPlans([]apim.ThrottlingPolicyInfo{{Name: "Bronze"}})
+	setDynamicPlans(nil)
+
+	tests := map[string]string{
+		ApplicationPlanID:       DefaultApplicationThrottlingPolicy,
+		"gold-plan":             "Gold",
+		dynamicPlanID("Bronze"): "Bronze",
+	}
+	for planID, expected := range tests {
+		policy, err := throttlingPolicyForPlan(planID, log.NewData())
+		if err != nil {
+			t.Error(planID, err)
+		}
+		if policy != expected {
+			t.Errorf(ErrMsgTestIncorrectResult, expected, policy)
+		}
+	}
+	_, err := throttlingPolicyForPlan("unknown-plan", log.NewData())
+	if err == nil {
+		t.Errorf(ErrMsgTestIncorrectResult, "error", err)
+	}
+
+	policy, err := instanceThrottlingPolicy(&model.ServiceInstance{}, log.NewData())
+	if err != nil {
+		t.Error(err)
+	}
+	if policy != DefaultApplicationThrottlingPolicy {
+		t.Errorf(ErrMsgTestIncorrectResult, DefaultApplicationThrottlingPolicy, policy)
+	}
+	policy, err = instanceThrottlingPolicy(&model.ServiceInstance{PlanID: "gold-plan", ThrottlingPolicy: "Silver"}, log.NewData())
+	if err != nil {
+		t.Error(err)
+	}
+	if policy != "Silver" {
+		t.Errorf(ErrMsgTestIncorrectResult, "Silver", policy)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pivotal-cf/brokerapi/domain"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	PlatformCloudFoundry     = "cloudfoundry"
+	PlatformKubernetes       = "kubernetes"
+	ContextKeyPlatform       = "platform"
+	ContextKeyOrganizationID = "organization_guid"
+	ContextKeySpaceID        = "space_guid"
+	ContextKeyNamespace      = "namespace"
+	ContextKeyClusterID      = "clusterid"
+	ContextKeyNameSuffix     = "_name"
+	LogKeyPlatform           = "platform"
+)
+
+// platformIdentifierKeys holds the context keys which identify the location of a service instance in each known
+// platform. All the keys are required. For other platforms the string values of the context are used, except the
+// display names such as "instance_name", which can be changed in the platform without moving the instance.
+var platformIdentifierKeys = map[string][]string{
+	PlatformCloudFoundry: {ContextKeyOrganizationID, ContextKeySpaceID},
+	PlatformKubernetes:   {ContextKeyNamespace, ContextKeyClusterID},
+}
+
+// platformContext represents the platform which requested a service instance and the identifiers of the instance
+// location in that platform, e.g. the organization and space in Cloud Foundry or the namespace and cluster in
+// Kubernetes.
+type platformContext struct {
+	platform    string
+	identifiers map[string]string
+}
+
+// readPlatformContext returns the platform context of the given provision request. Requests without a context are
+// treated as Cloud Foundry requests identified by the organization and space GUIDs.
+// Returns an error type mapped to apiresponses.FailureResponse if the context is invalid.
+func readPlatformContext(details *domain.ProvisionDetails, logData *log.Data) (*platformContext, error) {
+	rawContext := make(map[string]interface{})
+	if len(details.RawContext) != 0 {
+		err := json.Unmarshal(details.RawContext, &rawContext)
+		if err != nil {
+			log.Error("unable to parse the platform context", err, logData)
+			return nil, &mapBrokerError.ErrorInvalidPlatformContext{}
+		}
+	}
+	platform, _ := rawContext[ContextKeyPlatform].(string)
+	if platform == "" {
+		if !hasValidSpaceIDAndOrgID(details.SpaceGUID, details.OrganizationGUID) {
+			log.Error("platform is not given in the context", nil, logData)
+			return nil, &mapBrokerError.ErrorInvalidPlatformContext{}
+		}
+		platform = PlatformCloudFoundry
+	}
+	logData.Add(LogKeyPlatform, platform)
+	if platform == PlatformCloudFoundry {
+		// Cloud Foundry also sends the organization and space GUIDs as top level fields.
+		if _, ok := rawContext[ContextKeyOrganizationID]; !ok {
+			rawContext[ContextKeyOrganizationID] = details.OrganizationGUID
+		}
+		if _, ok := rawContext[ContextKeySpaceID]; !ok {
+			rawContext[ContextKeySpaceID] = details.SpaceGUID
+		}
+	}
+	identifiers := make(map[string]string)
+	keys, known := platformIdentifierKeys[platform]
+	if !known {
+		for key, val := range rawContext {
+			if s, ok := val.(string); ok && key != ContextKeyPlatform && !strings.HasSuffix(key, ContextKeyNameSuffix) {
+				identifiers[key] = s
+			}
+		}
+	}
+	for _, key := range keys {
+		val, _ := rawContext[key].(string)
+		if val == "" {
+			log.Error("required context value "+key+" is not given", nil, logData)
+			return nil, &mapBrokerError.ErrorInvalidPlatformContext{}
+		}
+		identifiers[key] = val
+	}
+	return &platformContext{
+		platform:    platform,
+		identifiers: identifiers,
+	}, nil
+}
+
+// identifiersJSON returns the JSON representation of the platform identifiers which is stored with the instance.
+func (p *platformContext) identifiersJSON() string {
+	// Marshalling a map of strings can't fail and the keys are sorted, hence the result can be compared.
+	b, _ := json.Marshal(p.identifiers)
+	return string(b)
+}
+
+// isSamePlatformContext returns true if the given service instance was created from the given platform context.
+func isSamePlatformContext(svcInstance *model.ServiceInstance, p *platformContext) bool {
+	if svcInstance.Platform == "" {
+		// Instances created before the platform context was stored are Cloud Foundry instances.
+		return p.platform == PlatformCloudFoundry &&
+			svcInstance.OrgID == p.identifiers[ContextKeyOrganizationID] &&
+			svcInstance.SpaceID == p.identifiers[ContextKeySpaceID]
+	}
+	return svcInstance.Platform == p.platform && svcInstance.PlatformContext == p.identifiersJSON()
+}
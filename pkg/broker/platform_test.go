@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pivotal-cf/brokerapi/domain"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+)
+
+func TestReadPlatformContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		details     domain.ProvisionDetails
+		platform    string
+		identifiers string
+		valid       bool
+	}{
+		{
+			name:        "no context",
+			details:     domain.ProvisionDetails{OrganizationGUID: "org", SpaceGUID: "space"},
+			platform:    PlatformCloudFoundry,
+			identifiers: `{"organization_guid":"org","space_guid":"space"}`,
+			valid:       true,
+		},
+		{
+			name: "cloud foundry",
+			details: domain.ProvisionDetails{RawContext: json.RawMessage(`{"platform":"cloudfoundry",` +
+				`"organization_guid":"org","space_guid":"space","instance_name":"db"}`)},
+			platform:    PlatformCloudFoundry,
+			identifiers: `{"organization_guid":"org","space_guid":"space"}`,
+			valid:       true,
+		},
+		{
+			name: "kubernetes",
+			details: domain.ProvisionDetails{RawContext: json.RawMessage(`{"platform":"kubernetes",` +
+				`"namespace":"default","clusterid":"c1","instance_name":"db"}`)},
+			platform:    PlatformKubernetes,
+			identifiers: `{"clusterid":"c1","namespace":"default"}`,
+			valid:       true,
+		},
+		{
+			name:    "kubernetes without cluster",
+			details: domain.ProvisionDetails{RawContext: json.RawMessage(`{"platform":"kubernetes","namespace":"default"}`)},
+			valid:   false,
+		},
+		{
+			name: "other platform",
+			details: domain.ProvisionDetails{RawContext: json.RawMessage(`{"platform":"nomad","region":"eu",` +
+				`"job":"j1","instance_name":"db","size":3,"instance_annotations":{"a":"b"}}`)},
+			platform:    "nomad",
+			identifiers: `{"job":"j1","region":"eu"}`,
+			valid:       true,
+		},
+		{
+			name:    "no platform",
+			details: domain.ProvisionDetails{RawContext: json.RawMessage(`{"region":"eu"}`)},
+			valid:   false,
+		},
+		{
+			name:    "invalid context",
+			details: domain.ProvisionDetails{RawContext: json.RawMessage(`[`)},
+			valid:   false,
+		},
+	}
+	for _, test := range tests {
+		p, err := readPlatformContext(&test.details, log.NewData())
+		if (err == nil) != test.valid {
+			t.Errorf(test.name+": "+ErrMsgTestIncorrectResult, test.valid, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if p.platform != test.platform {
+			t.Errorf(test.name+": "+ErrMsgTestIncorrectResult, test.platform, p.platform)
+		}
+		if p.identifiersJSON() != test.identifiers {
+			t.Errorf(test.name+": "+ErrMsgTestIncorrectResult, test.identifiers, p.identifiersJSON())
+		}
+	}
+}
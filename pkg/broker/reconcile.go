@@ -0,0 +1,294 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	ReconcilePath              = "/admin/reconcile"
+	DriftApplicationMissing    = "application-missing"
+	DriftSubscriptionMissing   = "subscription-missing"
+	DriftSubscriptionUnknown   = "subscription-unknown"
+	DriftKeysMissing           = "keys-missing"
+	QueryParamRepair           = "repair"
+	ErrMsgUnableToReconcile    = "unable to reconcile the service instances"
+	ErrMsgInvalidMethod        = "method not allowed"
+	ContentTypeApplicationJSON = "application/json"
+	OperationReconcile         = "reconcile"
+)
+
+var (
+	reconcileLock   sync.Mutex
+	reconcileRepair bool
+)
+
+// Drift represents a difference between a service instance in the database and its resources in API-M.
+type Drift struct {
+	InstanceID    string `json:"instanceId"`
+	ApplicationID string `json:"applicationId"`
+	Kind          string `json:"kind"`
+	Detail        string `json:"detail"`
+	Repaired      bool   `json:"repaired"`
+}
+
+// ReconcileReport represents the outcome of a reconciliation.
+type ReconcileReport struct {
+	Instances int     `json:"instances"`
+	Drifts    []Drift `json:"drifts"`
+}
+
+// initReconciler starts reconciling periodically the service instances with API-M.
+func initReconciler(conf *config.Reconcile) {
+	reconcileRepair = conf.Repair
+	if conf.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(conf.Interval) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			_, err := Reconcile(conf.Repair)
+			if err != nil {
+				log.Error(ErrMsgUnableToReconcile, err, nil)
+			}
+		}
+	}()
+}
+
+// ReconcileHandler returns the handler which reconciles the service instances on demand. The "repair" query parameter
+// overrides the configured repair mode. The reconciliation report is returned as JSON.
+func ReconcileHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, ErrMsgInvalidMethod, http.StatusMethodNotAllowed)
+			return
+		}
+		repair := reconcileRepair
+		if val := r.URL.Query().Get(QueryParamRepair); val != "" {
+			repair = val == "true"
+		}
+		report, err := Reconcile(repair)
+		if err != nil {
+			log.Error(ErrMsgUnableToReconcile, err, nil)
+			http.Error(w, ErrMsgUnableToReconcile, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeApplicationJSON)
+		err = json.NewEncoder(w).Encode(report)
+		if err != nil {
+			log.Error("unable to write the reconciliation report", err, nil)
+		}
+	})
+}
+
+// Reconcile compares the Applications, subscriptions and keys of the service instances in the database with API-M and
+// reports the differences. If repair is true, missing subscriptions and keys are re-created and the differences which
+// cannot be repaired are recorded in the service instance. Instances with an operation in progress or locked are
+// skipped.
+// Returns the reconciliation report and any error encountered.
+func Reconcile(repair bool) (*ReconcileReport, error) {
+	reconcileLock.Lock()
+	defer reconcileLock.Unlock()
+
+	var instances []model.ServiceInstance
+	_, err := db.RetrieveList(&model.ServiceInstance{}, &instances)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := apim.ListApplications()
+	if err != nil {
+		return nil, err
+	}
+	existingApps := make(map[string]bool)
+	for _, app := range apps {
+		existingApps[app.ApplicationID] = true
+	}
+
+	report := &ReconcileReport{
+		Drifts: []Drift{},
+	}
+	for _, instance := range instances {
+		drifts, ok := reconcileInstance(instance.ID, existingApps, repair)
+		if !ok {
+			continue
+		}
+		report.Instances++
+		report.Drifts = append(report.Drifts, drifts...)
+	}
+	return report, nil
+}
+
+// reconcileInstance reconciles the service instance of the given ID while holding its lock. The instance is read again
+// once locked since it may have been changed or removed meanwhile.
+// Returns the differences found and false if the instance is skipped.
+func reconcileInstance(svcInstanceID string, existingApps map[string]bool, repair bool) ([]Drift, bool) {
+	logData := log.NewData().Add(LogKeyInstanceID, svcInstanceID)
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil || op != nil {
+		return nil, false
+	}
+	lock, err := lockInstance(svcInstanceID, OperationReconcile, logData)
+	if err != nil {
+		return nil, false
+	}
+	defer lock.release()
+
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil || svcInstance == nil {
+		return nil, false
+	}
+	logData.Add(LogKeyAppID, svcInstance.ApplicationID)
+	var drifts []Drift
+	if !existingApps[svcInstance.ApplicationID] {
+		drifts = []Drift{newDrift(svcInstance, DriftApplicationMissing, svcInstance.ApplicationName)}
+	} else {
+		drifts = reconcileServiceInstance(svcInstance, repair, logData)
+	}
+	for _, drift := range drifts {
+		log.Info(fmt.Sprintf("drift %s: %s, repaired: %t", drift.Kind, drift.Detail, drift.Repaired), logData)
+	}
+	if repair {
+		recordDrift(svcInstance, drifts, logData)
+	}
+	return drifts, true
+}
+
+func newDrift(svcInstance *model.ServiceInstance, kind, detail string) Drift {
+	return Drift{
+		InstanceID:    svcInstance.ID,
+		ApplicationID: svcInstance.ApplicationID,
+		Kind:          kind,
+		Detail:        detail,
+	}
+}
+
+// reconcileServiceInstance compares the subscriptions and keys of the Application of the given instance with API-M.
+// Returns the differences found.
+func reconcileServiceInstance(svcInstance *model.ServiceInstance, repair bool, logData *log.Data) []Drift {
+	var drifts []Drift
+	subsResponses, err := apim.ListSubscriptions(svcInstance.ApplicationID)
+	if err != nil {
+		log.Error("unable to list subscriptions from API-M", err, logData)
+		return drifts
+	}
+	dbSubs, err := getSubscriptionsListForAppID(svcInstance.ApplicationID, logData)
+	if err != nil {
+		return drifts
+	}
+	apimSubs := make(map[string]bool)
+	for _, sub := range subsResponses {
+		apimSubs[sub.SubscriptionID] = true
+	}
+	knownSubs := make(map[string]bool)
+	for _, sub := range dbSubs {
+		knownSubs[sub.ID] = true
+		if apimSubs[sub.ID] {
+			continue
+		}
+		drift := newDrift(svcInstance, DriftSubscriptionMissing, sub.APIName+":"+sub.APIVersion)
+		if repair {
+			drift.Repaired = resubscribe(svcInstance, sub, logData)
+		}
+		drifts = append(drifts, drift)
+	}
+	for _, sub := range subsResponses {
+		if !knownSubs[sub.SubscriptionID] {
+			drifts = append(drifts, newDrift(svcInstance, DriftSubscriptionUnknown, sub.ApiInfo.Name+":"+sub.ApiInfo.Version))
+		}
+	}
+
+	app, err := apim.GetApplication(svcInstance.ApplicationID)
+	if err != nil {
+		log.Error("unable to retrieve the Application", err, logData)
+		return drifts
+	}
+	if applicationKeys(app.Keys, keyTypeOf(unmarshalKeyParams(svcInstance.KeyParameters))) == nil {
+		drift := newDrift(svcInstance, DriftKeysMissing, svcInstance.ApplicationName)
+		if repair {
+			drift.Repaired = regenerateKeys(svcInstance, logData)
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts
+}
+
+// resubscribe re-creates the given subscription which is missing in API-M and replaces it in the database.
+// Returns true if the subscription is re-created.
+func resubscribe(svcInstance *model.ServiceInstance, sub model.Subscription, logData *log.Data) bool {
+	subscriptions, err := createSubscriptions(svcInstance, []API{subscriptionAPI(sub)}, logData)
+	if err != nil {
+		log.Error("unable to re-create the subscription", err, logData)
+		return false
+	}
+	err = removeSubscription(sub.ID, svcInstance.ID)
+	if err == nil {
+		err = storeSubscriptions(subscriptions)
+	}
+	if err != nil {
+		log.Error("unable to replace the subscription in the database", err, logData)
+		unsubscribeMultipleAPIs(subscriptions, logData)
+		return false
+	}
+	return true
+}
+
+// regenerateKeys generates keys for the Application of the given instance and stores them in the instance.
+// Returns true if the keys are re-created.
+func regenerateKeys(svcInstance *model.ServiceInstance, logData *log.Data) bool {
+	keys, err := generateKeysForApplication(svcInstance.ApplicationID, unmarshalKeyParams(svcInstance.KeyParameters), logData)
+	if err != nil {
+		return false
+	}
+	svcInstance.ConsumerKey = keys.ConsumerKey
+	svcInstance.ConsumerSecret = keys.ConsumerSecret
+	return updateServiceInstanceRecord(svcInstance, logData) == nil
+}
+
+// recordDrift records the differences which are not repaired in the given instance. The recorded drift is cleared
+// once the instance is in sync with API-M.
+func recordDrift(svcInstance *model.ServiceInstance, drifts []Drift, logData *log.Data) {
+	var unrepaired []string
+	for _, drift := range drifts {
+		if !drift.Repaired {
+			unrepaired = append(unrepaired, drift.Kind+": "+drift.Detail)
+		}
+	}
+	description := strings.Join(unrepaired, "; ")
+	if svcInstance.Drift == description {
+		return
+	}
+	svcInstance.Drift = description
+	err := updateServiceInstanceRecord(svcInstance, logData)
+	if err != nil {
+		log.Error("unable to record the drift of the instance", err, logData)
+	}
+}
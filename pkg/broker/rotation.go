@@ -0,0 +1,228 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	RotateCredentialsPath = "/admin/rotate-credentials"
+	QueryParamInstance    = "instance"
+	OperationRotate       = "rotate"
+	ErrMsgUnableToRotate  = "unable to rotate the consumer secrets"
+	RotationCheckInterval = time.Hour
+	RotationMaxAgeUnit    = 24 * time.Hour
+)
+
+var (
+	rotationLock   sync.Mutex
+	rotationMaxAge time.Duration
+)
+
+// RotationReport represents the outcome of a consumer secret rotation.
+type RotationReport struct {
+	Rotated []string `json:"rotated"`
+	Failed  []string `json:"failed"`
+}
+
+// initCredentialRotation starts rotating periodically the consumer secrets of the service instances older than the
+// configured maximum age.
+func initCredentialRotation(conf *config.Rotation) {
+	rotationMaxAge = time.Duration(conf.MaxAge) * RotationMaxAgeUnit
+	if rotationMaxAge <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(RotationCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			_, err := RotateCredentials(nil, rotationMaxAge)
+			if err != nil {
+				log.Error(ErrMsgUnableToRotate, err, nil)
+			}
+		}
+	}()
+}
+
+// RotateCredentialsHandler returns the handler which rotates the consumer secrets on demand. The secrets of the
+// service instances given with the "instance" query parameter are rotated, or of all the service instances if none is
+// given. The rotation report is returned as JSON.
+func RotateCredentialsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, ErrMsgInvalidMethod, http.StatusMethodNotAllowed)
+			return
+		}
+		report, err := RotateCredentials(r.URL.Query()[QueryParamInstance], 0)
+		if err != nil {
+			log.Error(ErrMsgUnableToRotate, err, nil)
+			http.Error(w, ErrMsgUnableToRotate, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeApplicationJSON)
+		err = json.NewEncoder(w).Encode(report)
+		if err != nil {
+			log.Error("unable to write the rotation report", err, nil)
+		}
+	})
+}
+
+// RotateCredentials rotates the consumer secrets of the given service instances, or of all the service instances if
+// none is given. If maxAge is positive, only the secrets not rotated within maxAge are rotated; instances never rotated
+// start aging from now. Instances locked by an operation are reported as failed.
+// Returns the rotation report and any error encountered.
+func RotateCredentials(svcInstanceIDs []string, maxAge time.Duration) (*RotationReport, error) {
+	rotationLock.Lock()
+	defer rotationLock.Unlock()
+
+	if len(svcInstanceIDs) == 0 {
+		var instances []model.ServiceInstance
+		_, err := db.RetrieveList(&model.ServiceInstance{}, &instances)
+		if err != nil {
+			return nil, err
+		}
+		for _, svcInstance := range instances {
+			// skip the instances which are not due without locking them
+			if maxAge > 0 && svcInstance.SecretRotatedAt != nil && time.Since(*svcInstance.SecretRotatedAt) < maxAge {
+				continue
+			}
+			svcInstanceIDs = append(svcInstanceIDs, svcInstance.ID)
+		}
+	}
+
+	report := &RotationReport{
+		Rotated: []string{},
+		Failed:  []string{},
+	}
+	for _, svcInstanceID := range svcInstanceIDs {
+		logData := log.NewData().Add(LogKeyInstanceID, svcInstanceID)
+		rotated, err := rotateLockedInstanceCredentials(svcInstanceID, maxAge, logData)
+		if err != nil {
+			report.Failed = append(report.Failed, svcInstanceID)
+			continue
+		}
+		if rotated {
+			report.Rotated = append(report.Rotated, svcInstanceID)
+		}
+	}
+	return report, nil
+}
+
+// rotateLockedInstanceCredentials locks the given service instance and rotates its consumer secrets if they are
+// older than the given maximum age.
+// Returns true if the secrets are rotated and an error type mapped to apiresponses.FailureResponse if encountered.
+func rotateLockedInstanceCredentials(svcInstanceID string, maxAge time.Duration, logData *log.Data) (bool, error) {
+	lock, err := lockInstance(svcInstanceID, OperationRotate, logData)
+	if err != nil {
+		return false, err
+	}
+	defer lock.release()
+	svcInstance, err := retriveServiceInstance(svcInstanceID, logData)
+	if err != nil {
+		return false, err
+	}
+	if svcInstance == nil {
+		log.Error("instance doesn't exists", nil, logData)
+		return false, &mapBrokerError.ErrorUnableToRetrieveServiceInstance{}
+	}
+	if maxAge > 0 && svcInstance.SecretRotatedAt == nil {
+		now := time.Now()
+		svcInstance.SecretRotatedAt = &now
+		return false, updateServiceInstanceRecord(svcInstance, logData)
+	}
+	if maxAge > 0 && time.Since(*svcInstance.SecretRotatedAt) < maxAge {
+		return false, nil
+	}
+	err = rotateInstanceCredentials(svcInstance, logData)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// rotateInstanceCredentials regenerates in API-M the consumer secret of the Application of the given instance and of
+// the Applications of its Binds, and stores the new secrets. API-M revokes the previous secrets immediately.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func rotateInstanceCredentials(svcInstance *model.ServiceInstance, logData *log.Data) error {
+	logData.Add(LogKeyAppID, svcInstance.ApplicationID)
+	log.Debug("rotate the consumer secret", logData)
+	keys, err := apim.RegenerateConsumerSecret(svcInstance.ApplicationID, keyTypeOf(unmarshalKeyParams(svcInstance.KeyParameters)))
+	if err != nil {
+		log.Error("unable to regenerate the consumer secret", err, logData)
+		return &mapBrokerError.ErrorUnableToRotateSecret{}
+	}
+	now := time.Now()
+	svcInstance.ConsumerSecret = keys.ConsumerSecret
+	svcInstance.SecretRotatedAt = &now
+	err = updateServiceInstanceRecord(svcInstance, logData)
+	if err != nil {
+		log.Error("the regenerated consumer secret is not stored, the keys of the instance must be regenerated", err, logData)
+		return err
+	}
+
+	binds, err := retrieveBindApplications(svcInstance.ID, logData)
+	if err != nil {
+		return err
+	}
+	for i := range binds {
+		err = rotateBindCredentials(&binds[i], logData)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotateBindCredentials regenerates in API-M the consumer secret of the dedicated Application of the given Bind and
+// stores the new secret.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func rotateBindCredentials(bind *model.Bind, logData *log.Data) error {
+	keys, err := apim.RegenerateConsumerSecret(bind.ApplicationID, keyTypeOf(unmarshalKeyParams(bind.KeyParameters)))
+	if err != nil {
+		log.Error("unable to regenerate the consumer secret of the bind "+bind.ID, err, logData)
+		return &mapBrokerError.ErrorUnableToRotateSecret{}
+	}
+	bind.ConsumerSecret = keys.ConsumerSecret
+	err = db.Update(bind)
+	if err != nil {
+		log.Error("the regenerated consumer secret of the bind "+bind.ID+" is not stored", err, logData)
+		return &mapBrokerError.ErrorUnableToStoreBind{}
+	}
+	return nil
+}
+
+// isCredentialRotationOnly returns true if the given update parameters only request a consumer secret rotation.
+func isCredentialRotationOnly(rawParams json.RawMessage) bool {
+	if len(rawParams) == 0 {
+		return false
+	}
+	svcParams, err := unmarshalServiceParams(rawParams)
+	return err == nil && svcParams.RotateCredentials && len(svcParams.APIs) == 0
+}
@@ -0,0 +1,327 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/client"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	LogKeySagaID           = "saga-id"
+	DescOperationRecovered = "%s was interrupted by a broker restart and is rolled back"
+)
+
+var (
+	activeSagas = make(map[string]*saga)
+	sagasLock   sync.Mutex
+)
+
+// saga journals the API-M side effects of a multi-step operation of a service instance, so that an operation
+// interrupted by a crash can be rolled forward or compensated on the next start. Failures during the operation are
+// still reverted in memory; the journal is removed once the operation is finished.
+type saga struct {
+	record  *model.Saga
+	seq     int
+	logData *log.Data
+}
+
+// beginSaga stores a saga of the given operation type for the given instance. The side effects journaled for the
+// instance are recorded in the saga until it ends.
+// Returns the saga and an error type mapped to apiresponses.FailureResponse if encountered.
+func beginSaga(svcInstanceID, opType string, logData *log.Data) (*saga, error) {
+	s := &saga{
+		record: &model.Saga{
+			ID:            uuid.New().String(),
+			SVCInstanceID: svcInstanceID,
+			Type:          opType,
+		},
+		logData: logData,
+	}
+	err := db.Store(s.record)
+	if err != nil {
+		log.Error("unable to store the saga", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToStoreSaga{}
+	}
+	logData.Add(LogKeySagaID, s.record.ID)
+	sagasLock.Lock()
+	activeSagas[svcInstanceID] = s
+	sagasLock.Unlock()
+	return s, nil
+}
+
+// end removes the journal of the saga.
+func (s *saga) end() {
+	sagasLock.Lock()
+	if activeSagas[s.record.SVCInstanceID] == s {
+		delete(activeSagas, s.record.SVCInstanceID)
+	}
+	sagasLock.Unlock()
+	removeSaga(s.record, s.logData)
+}
+
+// journalStep stores the given step as pending in the active saga of the given instance before the side effect is
+// performed. A nil step is returned if the instance has no active saga.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func journalStep(svcInstanceID string, step *model.SagaStep) (*model.SagaStep, error) {
+	sagasLock.Lock()
+	s := activeSagas[svcInstanceID]
+	if s != nil {
+		s.seq++
+		step.Seq = s.seq
+	}
+	sagasLock.Unlock()
+	if s == nil {
+		return nil, nil
+	}
+	step.ID = uuid.New().String()
+	step.SagaID = s.record.ID
+	step.State = model.SagaStepStatePending
+	err := db.Store(step)
+	if err != nil {
+		log.Error("unable to journal the step "+step.Action, err, s.logData)
+		return nil, &mapBrokerError.ErrorUnableToStoreSaga{}
+	}
+	return step, nil
+}
+
+// completeStep marks the given journaled step as done after the side effect is performed on the given resource.
+func completeStep(step *model.SagaStep, resourceID string, logData *log.Data) {
+	if step == nil {
+		return
+	}
+	step.State = model.SagaStepStateDone
+	step.ResourceID = resourceID
+	err := db.Update(step)
+	if err != nil {
+		log.Error("unable to complete the step "+step.Action, err, logData)
+	}
+}
+
+func removeSaga(record *model.Saga, logData *log.Data) {
+	var steps []model.SagaStep
+	_, err := db.RetrieveList(&model.SagaStep{SagaID: record.ID}, &steps)
+	if err != nil {
+		log.Error("unable to retrieve the saga steps", err, logData)
+		return
+	}
+	for i := range steps {
+		err = db.Delete(&model.SagaStep{ID: steps[i].ID})
+		if err != nil {
+			log.Error("unable to delete the saga step", err, logData)
+			return
+		}
+	}
+	err = db.Delete(&model.Saga{ID: record.ID})
+	if err != nil {
+		log.Error("unable to delete the saga", err, logData)
+	}
+}
+
+// recoverSagas rolls back the operations interrupted by a broker restart. The side effects journaled by an interrupted
+// operation are compensated in reverse order, except removed subscriptions which are rolled forward. The instances of
+// interrupted provisions are removed. A saga is kept for the next start if its recovery fails.
+// Sagas of locked instances may belong to operations running on other broker replicas, so they are recovered when
+// the lock of the instance expires and is taken over.
+func recoverSagas() {
+	var sagas []model.Saga
+	_, err := db.RetrieveList(&model.Saga{}, &sagas)
+	if err != nil {
+		log.Error("unable to retrieve the interrupted sagas", err, nil)
+		return
+	}
+	for i := range sagas {
+		logData := log.NewData().Add(LogKeyInstanceID, sagas[i].SVCInstanceID)
+		locked, err := isInstanceLocked(sagas[i].SVCInstanceID, logData)
+		if err != nil || locked {
+			continue
+		}
+		recoverInterruptedSaga(&sagas[i], logData)
+	}
+}
+
+// recoverInstanceSagas rolls back the operations of the given instance interrupted by a broker restart.
+// Returns false if any saga of the instance cannot be recovered.
+func recoverInstanceSagas(svcInstanceID string, logData *log.Data) bool {
+	var sagas []model.Saga
+	_, err := db.RetrieveList(&model.Saga{SVCInstanceID: svcInstanceID}, &sagas)
+	if err != nil {
+		log.Error("unable to retrieve the interrupted sagas", err, logData)
+		return false
+	}
+	for i := range sagas {
+		if !recoverInterruptedSaga(&sagas[i], log.NewData().Add(LogKeyInstanceID, svcInstanceID)) {
+			return false
+		}
+	}
+	return true
+}
+
+func recoverInterruptedSaga(record *model.Saga, logData *log.Data) bool {
+	logData.Add(LogKeySagaID, record.ID).
+		Add(LogKeyOperationType, record.Type)
+	log.Info("recover the interrupted operation", logData)
+	if !recoverSaga(record, logData) {
+		return false
+	}
+	removeSaga(record, logData)
+	return true
+}
+
+// recoverSaga compensates or rolls forward the journaled steps of the given saga.
+// Returns true if all the steps are recovered.
+func recoverSaga(record *model.Saga, logData *log.Data) bool {
+	var steps []model.SagaStep
+	_, err := db.RetrieveList(&model.SagaStep{SagaID: record.ID}, &steps)
+	if err != nil {
+		log.Error("unable to retrieve the saga steps", err, logData)
+		return false
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].Seq > steps[j].Seq
+	})
+	for i := range steps {
+		err = recoverStep(record, &steps[i])
+		if err != nil {
+			log.Error("unable to recover the step "+steps[i].Action, err, logData)
+			return false
+		}
+	}
+	if record.Type == model.OperationProvision {
+		removeInstanceRecords(record.SVCInstanceID, logData)
+	}
+	failInterruptedOperation(record, logData)
+	return true
+}
+
+// recoverStep compensates the given step, or rolls it forward if it removed a subscription. A pending step is
+// resolved by looking up the resource it may have affected. Resources which no longer exist are ignored.
+func recoverStep(record *model.Saga, step *model.SagaStep) error {
+	switch step.Action {
+	case model.SagaStepCreateApplication:
+		appID := step.ResourceID
+		if appID == "" {
+			apps, err := apim.SearchApplications(step.ResourceName)
+			if err != nil {
+				return err
+			}
+			for _, app := range apps {
+				if app.Name == step.ResourceName {
+					appID = app.ApplicationID
+				}
+			}
+		}
+		if appID == "" {
+			return nil
+		}
+		return ignoreNotFound(apim.DeleteApplication(appID))
+	case model.SagaStepCreateSubscription:
+		subID := step.ResourceID
+		if subID == "" {
+			subs, err := apim.ListSubscriptions(step.ApplicationID)
+			if err != nil {
+				return ignoreNotFound(err)
+			}
+			for _, sub := range subs {
+				if sub.ApiID == step.APIID {
+					subID = sub.SubscriptionID
+				}
+			}
+		}
+		if subID == "" {
+			return nil
+		}
+		err := ignoreNotFound(apim.UnSubscribe(subID))
+		if err != nil {
+			return err
+		}
+		return db.Delete(&model.Subscription{ID: subID})
+	case model.SagaStepDeleteSubscription:
+		err := ignoreNotFound(apim.UnSubscribe(step.ResourceID))
+		if err != nil {
+			return err
+		}
+		return removeSubscription(step.ResourceID, record.SVCInstanceID)
+	case model.SagaStepCreateAPI:
+		apiID := step.ResourceID
+		if apiID == "" {
+			id, err := apim.SearchAPIByNameVersion(step.ResourceName, step.ResourceVersion)
+			if _, ok := err.(*client.InvokeError); ok {
+				return err
+			}
+			if err != nil {
+				// the API was not created
+				return nil
+			}
+			apiID = id
+		}
+		return ignoreNotFound(apim.DeleteAPI(apiID))
+	}
+	return nil
+}
+
+func ignoreNotFound(err error) error {
+	if e, ok := err.(*client.InvokeError); ok && e.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// removeInstanceRecords removes the instance of an interrupted provision from the database.
+func removeInstanceRecords(svcInstanceID string, logData *log.Data) {
+	for _, e := range []model.Entity{
+		&model.ServiceInstance{ID: svcInstanceID},
+		&model.APIInstance{ID: svcInstanceID},
+		&model.SubscriptionInstance{ID: svcInstanceID},
+	} {
+		err := db.Delete(e)
+		if err != nil {
+			log.Error("unable to delete the instance of the interrupted provision", err, logData)
+		}
+	}
+}
+
+// failInterruptedOperation marks the in progress operation of the given saga as failed.
+func failInterruptedOperation(record *model.Saga, logData *log.Data) {
+	var operations []model.Operation
+	_, err := db.RetrieveList(&model.Operation{
+		SVCInstanceID: record.SVCInstanceID,
+		Type:          record.Type,
+		State:         model.OperationStateInProgress,
+	}, &operations)
+	if err != nil {
+		log.Error("unable to retrieve the interrupted operation", err, logData)
+		return
+	}
+	for i := range operations {
+		operations[i].State = model.OperationStateFailed
+		operations[i].Description = fmt.Sprintf(DescOperationRecovered, record.Type)
+		updateOperationAndLogError(&operations[i], logData)
+	}
+}
@@ -0,0 +1,436 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/pivotal-cf/brokerapi/domain"
+	"github.com/pivotal-cf/brokerapi/domain/apiresponses"
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/client"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+	"github.com/wso2/openservicebroker-apim/pkg/utils"
+)
+
+const (
+	SubscriptionPlanID          = "9d3c1a7e-2f4b-4e8d-8c61-5a0b7e2f9c14"
+	SubscriptionPlanName        = "subscription"
+	SubscriptionPlanDescription = "Subscribes an existing Application to an API in WSO2 API Manager"
+	LogKeySubscriptionID        = "subscription-id"
+)
+
+var (
+	subscriptionPlanEnabled              bool
+	subscriptionPlanBindable             = false
+	subscriptionPlanInputParameterSchema map[string]interface{}
+)
+
+// initSubscriptionPlan initializes the subscription plan if it is enabled in the catalog configuration.
+func initSubscriptionPlan(enabled bool) {
+	subscriptionPlanEnabled = enabled
+	if !enabled {
+		return
+	}
+	var err error
+	subscriptionPlanInputParameterSchema, err = utils.GetJSONSchema(apim.SubscriptionPlanInputParameterSchemaRaw)
+	if err != nil {
+		log.HandleErrorAndExit(fmt.Sprintf(ErrMsgUnableToGenInputSchema, SubscriptionPlanName), err)
+	}
+}
+
+// isSubscriptionPlan returns true if the subscription plan is enabled and the given plan ID is the ID of the
+// subscription plan.
+func isSubscriptionPlan(planID string) bool {
+	return subscriptionPlanEnabled && planID == SubscriptionPlanID
+}
+
+// subscriptionServicePlan returns the catalog entry of the subscription plan.
+func subscriptionServicePlan() domain.ServicePlan {
+	return domain.ServicePlan{
+		ID:          SubscriptionPlanID,
+		Name:        SubscriptionPlanName,
+		Description: SubscriptionPlanDescription,
+		Bindable:    &subscriptionPlanBindable,
+		Schemas: &domain.ServiceSchemas{
+			Instance: domain.ServiceInstanceSchema{
+				Create: domain.Schema{
+					Parameters: subscriptionPlanInputParameterSchema,
+				},
+				Update: domain.Schema{
+					Parameters: subscriptionPlanInputParameterSchema,
+				},
+			},
+		},
+	}
+}
+
+// getSubscriptionPlanParams parses and validates the parameters of a subscription plan request.
+func getSubscriptionPlanParams(rawParams json.RawMessage, logData *log.Data) (*apim.SubscriptionParam, error) {
+	var params apim.SubscriptionParam
+	err := json.Unmarshal(rawParams, &params)
+	if err != nil {
+		log.Error("unable to parse the subscription plan parameters", err, logData)
+		return nil, &mapBrokerError.ErrorInvalidSubscriptionPlanParameters{}
+	}
+	if params.SubsSpec.APIName == "" || params.SubsSpec.AppName == "" {
+		log.Error("API name or Application name of the subscription is missing", nil, logData)
+		return nil, &mapBrokerError.ErrorInvalidSubscriptionPlanParameters{}
+	}
+	logData.
+		Add(LogKeyAPIName, params.SubsSpec.APIName).
+		Add(LogKeyApplicationName, params.SubsSpec.AppName)
+	return &params, nil
+}
+
+func generateHashForSubscriptionParams(params *apim.SubscriptionParam, logData *log.Data) (string, error) {
+	generatedHash, err := hashstructure.Hash(params, nil)
+	if err != nil {
+		log.Error("unable to generate hash value for subscription plan parameters", err, logData)
+		return "", &mapBrokerError.ErrorUnableToGenerateHash{}
+	}
+	return strconv.FormatUint(generatedHash, 10), nil
+}
+
+// subscriptionSpecAPI returns the API parameter of the given subscription spec.
+func subscriptionSpecAPI(spec *apim.SubscriptionSpec) API {
+	return API{
+		Name:    spec.APIName,
+		Version: spec.APIVersion,
+		Tier:    spec.SubscriptionTier,
+	}
+}
+
+// provisionSubscriptionPlan handles a provision request of the subscription plan.
+func provisionSubscriptionPlan(svcInstanceID string, details *domain.ProvisionDetails, asyncAllowed bool, logData *log.Data) (domain.ProvisionedServiceSpec, error) {
+	platform, err := readPlatformContext(details, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	params, err := getSubscriptionPlanParams(details.RawParameters, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	paramHash, err := generateHashForSubscriptionParams(params, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	requestHash, err := generateProvisionRequestHash(details.PlanID, paramHash, platform, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+
+	op, err := retrieveInProgressOperation(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if op != nil {
+		return provisionInProgress(op, requestHash, asyncAllowed)
+	}
+	lock, err := lockInstance(svcInstanceID, model.OperationProvision, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	defer lock.release()
+
+	subsInstance, err := retrieveSubscriptionInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if subsInstance != nil {
+		if subsInstance.ParameterHash == paramHash && subsInstance.Platform == platform.platform &&
+			subsInstance.PlatformContext == platform.identifiersJSON() {
+			return domain.ProvisionedServiceSpec{
+				AlreadyExists: true,
+			}, nil
+		}
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+	exists, err := isExistingInstance(svcInstanceID, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if exists {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+
+	subsInstance = &model.SubscriptionInstance{
+		ID:              svcInstanceID,
+		ServiceID:       details.ServiceID,
+		PlanID:          details.PlanID,
+		ApplicationName: params.SubsSpec.AppName,
+		Tier:            params.SubsSpec.SubscriptionTier,
+		Parameters:      string(details.RawParameters),
+		ParameterHash:   paramHash,
+		Platform:        platform.platform,
+		PlatformContext: platform.identifiersJSON(),
+	}
+	if asyncAllowed {
+		op, err := startRequestOperation(svcInstanceID, model.OperationProvision, requestHash, func() error {
+			return createSubscriptionInstance(subsInstance, params, logData)
+		}, logData)
+		if err != nil {
+			return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.ProvisionedServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err = createSubscriptionInstance(subsInstance, params, logData)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	return domain.ProvisionedServiceSpec{}, nil
+}
+
+// createSubscriptionInstance subscribes the given Application to the given API in API-M and stores the given
+// subscription instance. The subscription is removed if it cannot be stored. Returns any error encountered.
+func createSubscriptionInstance(subsInstance *model.SubscriptionInstance, params *apim.SubscriptionParam, logData *log.Data) error {
+	appID, err := apim.SearchApplication(params.SubsSpec.AppName)
+	if err != nil {
+		log.Error("unable to find the Application", err, logData)
+		return &mapBrokerError.ErrorUnableToFindApplication{
+			AppName: params.SubsSpec.AppName,
+		}
+	}
+	logData.Add(LogKeyAppID, appID)
+	api, err := resolveAPI(subscriptionSpecAPI(&params.SubsSpec), logData)
+	if err != nil {
+		return err
+	}
+	logData.Add(LogKeyAPIID, api.ID)
+	err = validateSubscriptionTier(api.ID, api, logData)
+	if err != nil {
+		return err
+	}
+	s, err := beginSaga(subsInstance.ID, model.OperationProvision, logData)
+	if err != nil {
+		return err
+	}
+	defer s.end()
+	step, err := journalStep(subsInstance.ID, &model.SagaStep{
+		Action:        model.SagaStepCreateSubscription,
+		ApplicationID: appID,
+		APIID:         api.ID,
+	})
+	if err != nil {
+		return err
+	}
+	sub, err := apim.CreateSubscription(&apim.SubscriptionReq{
+		ApiID:            api.ID,
+		ApplicationID:    appID,
+		ThrottlingPolicy: subscriptionTier(api),
+	})
+	if err != nil {
+		log.Error("unable to create the subscription", err, logData)
+		return handleAPIMResourceCreateError(err, api.Name, logData)
+	}
+	completeStep(step, sub.SubscriptionID, logData)
+	logData.Add(LogKeySubscriptionID, sub.SubscriptionID)
+	subsInstance.SubscriptionID = sub.SubscriptionID
+	subsInstance.ApplicationID = appID
+	subsInstance.APIID = api.ID
+	subsInstance.APIName = api.Name
+	subsInstance.APIVersion = api.Version
+	err = db.Store(subsInstance)
+	if err != nil {
+		log.Error("unable to store the subscription instance", err, logData)
+		revertSubscription(sub.SubscriptionID, logData)
+		return &mapBrokerError.ErrorUnableToStoreSubscriptionInstance{}
+	}
+	return nil
+}
+
+func revertSubscription(subscriptionID string, logData *log.Data) {
+	err := apim.UnSubscribe(subscriptionID)
+	if err != nil {
+		log.Error("unable to remove the subscription", err, logData)
+	}
+}
+
+// updateSubscriptionPlan handles an update request of a subscription plan instance. Only the tier of the subscription
+// can be changed.
+func updateSubscriptionPlan(subsInstance *model.SubscriptionInstance, details *domain.UpdateDetails, asyncAllowed bool, logData *log.Data) (domain.UpdateServiceSpec, error) {
+	if details.PlanID != "" && details.PlanID != subsInstance.PlanID {
+		log.Error("plan of a subscription instance cannot be changed", nil, logData)
+		return domain.UpdateServiceSpec{}, apiresponses.ErrPlanChangeNotSupported
+	}
+	params, err := getSubscriptionPlanParams(details.RawParameters, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	paramHash, err := generateHashForSubscriptionParams(params, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	if paramHash == subsInstance.ParameterHash {
+		log.Debug("subscription parameters are not changed", logData)
+		return domain.UpdateServiceSpec{}, nil
+	}
+	spec := params.SubsSpec
+	if spec.AppName != subsInstance.ApplicationName || spec.APIName != subsInstance.APIName ||
+		(spec.APIVersion != "" && spec.APIVersion != subsInstance.APIVersion && spec.APIVersion != requestedAPIVersion(subsInstance)) {
+		log.Error("Application or API of a subscription instance cannot be changed", nil, logData)
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(&mapBrokerError.ErrorSubscriptionPlanChangeNotSupported{})
+	}
+	subsInstance.Parameters = string(details.RawParameters)
+	subsInstance.ParameterHash = paramHash
+
+	if asyncAllowed {
+		op, err := startOperation(subsInstance.ID, model.OperationUpdate, func() error {
+			return updateSubscriptionInstance(subsInstance, spec.SubscriptionTier, logData)
+		}, logData)
+		if err != nil {
+			return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.UpdateServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err = updateSubscriptionInstance(subsInstance, spec.SubscriptionTier, logData)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	return domain.UpdateServiceSpec{}, nil
+}
+
+// requestedAPIVersion returns the API version given in the parameters of the given subscription instance, which may be
+// a version selector such as "1.x". The resolved version is returned if the parameters don't give a version.
+func requestedAPIVersion(subsInstance *model.SubscriptionInstance) string {
+	var params apim.SubscriptionParam
+	err := json.Unmarshal([]byte(subsInstance.Parameters), &params)
+	if err != nil || params.SubsSpec.APIVersion == "" {
+		return subsInstance.APIVersion
+	}
+	return params.SubsSpec.APIVersion
+}
+
+// updateSubscriptionInstance changes the tier of the subscription of the given subscription instance and updates the
+// instance. Returns any error encountered.
+func updateSubscriptionInstance(subsInstance *model.SubscriptionInstance, tier string, logData *log.Data) error {
+	api := API{
+		ID:   subsInstance.APIID,
+		Name: subsInstance.APIName,
+		Tier: tier,
+	}
+	if subscriptionTier(api) != subscriptionTier(API{Tier: subsInstance.Tier}) {
+		err := validateSubscriptionTier(api.ID, api, logData)
+		if err != nil {
+			return err
+		}
+		err = apim.UpdateSubscription(subsInstance.SubscriptionID, &apim.SubscriptionReq{
+			ApiID:            subsInstance.APIID,
+			ApplicationID:    subsInstance.ApplicationID,
+			ThrottlingPolicy: subscriptionTier(api),
+		})
+		if err != nil {
+			log.Error("unable to update the subscription", err, logData)
+			return &mapBrokerError.ErrorUnableToUpdateSubscription{}
+		}
+		subsInstance.Tier = tier
+	}
+	err := db.Update(subsInstance)
+	if err != nil {
+		log.Error("unable to update the subscription instance", err, logData)
+		return &mapBrokerError.ErrorUnableToStoreSubscriptionInstance{}
+	}
+	return nil
+}
+
+// deprovisionSubscriptionPlan handles a deprovision request of a subscription plan instance.
+func deprovisionSubscriptionPlan(subsInstance *model.SubscriptionInstance, asyncAllowed bool, logData *log.Data) (domain.DeprovisionServiceSpec, error) {
+	if asyncAllowed {
+		op, err := startOperation(subsInstance.ID, model.OperationDeprovision, func() error {
+			return deprovisionSubscriptionInstance(subsInstance, logData)
+		}, logData)
+		if err != nil {
+			return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+		}
+		return domain.DeprovisionServiceSpec{
+			IsAsync:       true,
+			OperationData: op.ID,
+		}, nil
+	}
+
+	err := deprovisionSubscriptionInstance(subsInstance, logData)
+	if err != nil {
+		return domain.DeprovisionServiceSpec{}, mapBrokerError.MapBrokerErrors(err)
+	}
+	return domain.DeprovisionServiceSpec{}, nil
+}
+
+// deprovisionSubscriptionInstance removes only the subscription of the given subscription instance in API-M and
+// deletes the instance from the database. The Application is kept. A subscription which is already removed in API-M is
+// ignored. Returns any error encountered.
+func deprovisionSubscriptionInstance(subsInstance *model.SubscriptionInstance, logData *log.Data) error {
+	log.Debug("remove the subscription", logData)
+	err := apim.UnSubscribe(subsInstance.SubscriptionID)
+	if err != nil {
+		e, ok := err.(*client.InvokeError)
+		if !ok || e.StatusCode != http.StatusNotFound {
+			log.Error("unable to remove the subscription", err, logData)
+			return &mapBrokerError.ErrorUnableToDeleteAPIMResource{}
+		}
+		log.Debug("subscription doesn't exist in API-M", logData)
+	}
+	err = db.Delete(&model.SubscriptionInstance{ID: subsInstance.ID})
+	if err != nil {
+		log.Error("unable to delete the subscription instance from the database", err, logData)
+		return &mapBrokerError.ErrorUnableToDeleteInstance{}
+	}
+	return nil
+}
+
+// subscriptionInstanceDetails returns the details of the given subscription plan instance.
+func subscriptionInstanceDetails(subsInstance *model.SubscriptionInstance) domain.GetInstanceDetailsSpec {
+	return domain.GetInstanceDetailsSpec{
+		ServiceID:  subsInstance.ServiceID,
+		PlanID:     subsInstance.PlanID,
+		Parameters: json.RawMessage(subsInstance.Parameters),
+	}
+}
+
+// retrieveSubscriptionInstance returns the subscription plan instance with the given ID or nil if it doesn't exist.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func retrieveSubscriptionInstance(svcInstanceID string, logData *log.Data) (*model.SubscriptionInstance, error) {
+	subsInstance := &model.SubscriptionInstance{
+		ID: svcInstanceID,
+	}
+	exists, err := db.Retrieve(subsInstance)
+	if err != nil {
+		log.Error("unable to retrieve the subscription instance from database", err, logData)
+		return nil, &mapBrokerError.ErrorUnableToRetrieveSubscriptionInstance{}
+	}
+	if !exists {
+		return nil, nil
+	}
+	logData.Add(LogKeySubscriptionID, subsInstance.SubscriptionID)
+	return subsInstance, nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package broker
+
+import (
+	"github.com/wso2/openservicebroker-apim/pkg/apim"
+	"github.com/wso2/openservicebroker-apim/pkg/db"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/mapBrokerError"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	DefaultSubscriptionTier = "Unlimited"
+	LogKeyTier              = "tier"
+)
+
+// subscriptionTier returns the subscription tier requested for the given API.
+func subscriptionTier(api API) string {
+	if api.Tier == "" {
+		return DefaultSubscriptionTier
+	}
+	return api.Tier
+}
+
+// validateSubscriptionTier checks whether the tier requested for the given API is offered by the API. The default tier
+// is not validated.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func validateSubscriptionTier(apiID string, api API, logData *log.Data) error {
+	if api.Tier == "" {
+		return nil
+	}
+	storeAPI, err := apim.GetStoreAPI(apiID)
+	if err != nil {
+		log.Error("unable to retrieve the API", err, logData)
+		return &mapBrokerError.ErrorUnableToSearchAPIs{}
+	}
+	for _, tier := range storeAPI.Tiers {
+		if tier.TierName == api.Tier {
+			return nil
+		}
+	}
+	logData.Add(LogKeyTier, api.Tier)
+	log.Error("tier is not available for the API "+api.Name, nil, logData)
+	return &mapBrokerError.ErrorInvalidSubscriptionTier{
+		APIName: api.Name,
+		Tier:    api.Tier,
+	}
+}
+
+// getTierChangedAPIs returns the requested APIs which are already subscribed with a different tier.
+func getTierChangedAPIs(existingAPIs, requestedAPIs []API) []API {
+	var changedAPIs []API
+	for _, api := range requestedAPIs {
+		for _, existingAPI := range existingAPIs {
+			if isSameAPI(api, existingAPI) && subscriptionTier(api) != subscriptionTier(existingAPI) {
+				changedAPIs = append(changedAPIs, api)
+			}
+		}
+	}
+	return changedAPIs
+}
+
+// updateServiceForChangedTiers updates in place the subscriptions of the given instance whose requested tier differs
+// from the subscribed one. The requested APIs must be resolved.
+// Returns an error type mapped to apiresponses.FailureResponse if encountered.
+func updateServiceForChangedTiers(existingAPIs, requestedAPIs []API, svcInstance *model.ServiceInstance, logData *log.Data) error {
+	for _, api := range getTierChangedAPIs(existingAPIs, requestedAPIs) {
+		sub, err := getSubscriptionForAppAndAPI(svcInstance.ApplicationID, api, logData)
+		if err != nil {
+			return err
+		}
+		err = validateSubscriptionTier(api.ID, api, logData)
+		if err != nil {
+			return err
+		}
+		err = apim.UpdateSubscription(sub.ID, &apim.SubscriptionReq{
+			ApplicationID:    svcInstance.ApplicationID,
+			ApiID:            api.ID,
+			ThrottlingPolicy: subscriptionTier(api),
+		})
+		if err != nil {
+			log.Error("unable to update the subscription tier", err, logData)
+			return &mapBrokerError.ErrorUnableToUpdateSubscription{}
+		}
+		sub.Tier = subscriptionTier(api)
+		err = db.Update(sub)
+		if err != nil {
+			log.Error("unable to update the subscription in the database", err, logData)
+			return &mapBrokerError.ErrorUnableToUpdateSubscription{}
+		}
+	}
+	return nil
+}
@@ -43,13 +43,18 @@ const (
 
 // DB represent the Database configuration.
 type DB struct {
-	Host       string `mapstructure:"host"`
-	Port       int    `mapstructure:"port"`
-	Username   string `mapstructure:"username"`
-	Password   string `mapstructure:"password"`
-	Database   string `mapstructure:"database"`
-	LogMode    bool   `mapstructure:"logMode"`
-	MaxRetries int    `mapstructure:"maxRetries"`
+	Type        string `mapstructure:"type"`
+	Host        string `mapstructure:"host"`
+	Port        int    `mapstructure:"port"`
+	Username    string `mapstructure:"username"`
+	Password    string `mapstructure:"password"`
+	Database    string `mapstructure:"database"`
+	SSLMode     string `mapstructure:"sslMode"`
+	SSLRootCert string `mapstructure:"sslRootCert"`
+	LogMode     bool   `mapstructure:"logMode"`
+	MaxRetries  int    `mapstructure:"maxRetries"`
+	// MigrateOnStartup applies the pending schema migrations when the broker starts
+	MigrateOnStartup bool `mapstructure:"migrateOnStartup"`
 }
 
 // APIM represents the information required to interact with the APIM.
@@ -65,6 +70,8 @@ type APIM struct {
 	StoreSubscriptionContext         string `mapstructure:"storeSubscriptionContext"`
 	StoreMultipleSubscriptionContext string `mapstructure:"storeMultipleSubscriptionContext"`
 	StoreEndpoint                    string `mapstructure:"storeEndpoint"`
+	StoreThrottlingPolicyContext     string `mapstructure:"storeThrottlingPolicyContext"`
+	StoreAPIContext                  string `mapstructure:"storeAPIContext"`
 }
 
 // Auth represents the username and the password for basic auth.
@@ -109,12 +116,77 @@ type HTTP struct {
 	Client Client `mapstructure:"client"`
 }
 
+// Operation represents the configuration for asynchronous operations.
+type Operation struct {
+	Workers   int `mapstructure:"workers"`
+	QueueSize int `mapstructure:"queueSize"`
+	Timeout   int `mapstructure:"timeout"`
+}
+
+// Instance represents the configuration related to service instances.
+type Instance struct {
+	LiveLookup          bool `mapstructure:"liveLookup"`
+	FollowInterval      int  `mapstructure:"followInterval"`
+	OrphanSweepInterval int  `mapstructure:"orphanSweepInterval"`
+}
+
+// Reconcile represents the configuration of the reconciliation between the database and API-M.
+type Reconcile struct {
+	Interval int  `mapstructure:"interval"`
+	Repair   bool `mapstructure:"repair"`
+}
+
+// Rotation represents the configuration of the consumer secret rotation.
+type Rotation struct {
+	MaxAge int `mapstructure:"maxAge"`
+}
+
+// CredentialTemplate represents a named Go template which renders the bind credentials as a JSON object.
+type CredentialTemplate struct {
+	Name     string `mapstructure:"name"`
+	Template string `mapstructure:"template"`
+}
+
+// Bind represents the configuration related to binds.
+type Bind struct {
+	CredentialMode     string               `mapstructure:"credentialMode"`
+	CredentialsVersion int                  `mapstructure:"credentialsVersion"`
+	TokenEndpoint      string               `mapstructure:"tokenEndpoint"`
+	CredentialTemplate string               `mapstructure:"credentialTemplate"`
+	Templates          []CredentialTemplate `mapstructure:"templates"`
+}
+
+// Plan represents an Application plan mapped to an API-M Application throttling policy.
+type Plan struct {
+	ID                 string `mapstructure:"id"`
+	Name               string `mapstructure:"name"`
+	Description        string `mapstructure:"description"`
+	ThrottlingPolicy   string `mapstructure:"throttlingPolicy"`
+	CredentialTemplate string `mapstructure:"credentialTemplate"`
+}
+
+// Catalog represents the configuration of the service catalog.
+type Catalog struct {
+	Plans            []Plan `mapstructure:"plans"`
+	Dynamic          bool   `mapstructure:"dynamic"`
+	RefreshInterval  int    `mapstructure:"refreshInterval"`
+	ListAPIs         bool   `mapstructure:"listAPIs"`
+	APIPlan          bool   `mapstructure:"apiPlan"`
+	SubscriptionPlan bool   `mapstructure:"subscriptionPlan"`
+}
+
 // Broker main struct which holds  sub configurations.
 type Broker struct {
-	Log  Log  `mapstructure:"log"`
-	HTTP HTTP `mapstructure:"http"`
-	APIM APIM `mapstructure:"apim"`
-	DB   DB   `mapstructure:"db"`
+	Log       Log       `mapstructure:"log"`
+	HTTP      HTTP      `mapstructure:"http"`
+	APIM      APIM      `mapstructure:"apim"`
+	DB        DB        `mapstructure:"db"`
+	Operation Operation `mapstructure:"operation"`
+	Instance  Instance  `mapstructure:"instance"`
+	Bind      Bind      `mapstructure:"bind"`
+	Catalog   Catalog   `mapstructure:"catalog"`
+	Reconcile Reconcile `mapstructure:"reconcile"`
+	Rotation  Rotation  `mapstructure:"rotation"`
 }
 
 // Load loads configuration into Broker object.
@@ -181,12 +253,41 @@ func setDefaultConf() {
 	viper.SetDefault("apim.storeApplicationContext", "/api/am/store/v1/applications")
 	viper.SetDefault("apim.storeSubscriptionContext", "/api/am/store/v1/subscriptions")
 	viper.SetDefault("apim.storeMultipleSubscriptionContext", "/api/am/store/v1/subscriptions/multiple")
+	viper.SetDefault("apim.storeThrottlingPolicyContext", "/api/am/store/v1/throttling-policies/application")
+	viper.SetDefault("apim.storeAPIContext", "/api/am/store/v1/apis")
 
+	viper.SetDefault("db.type", "mysql")
 	viper.SetDefault("db.host", "localhost")
 	viper.SetDefault("db.port", "3306")
 	viper.SetDefault("db.username", "root")
 	viper.SetDefault("db.password", "root123")
 	viper.SetDefault("db.database", "broker")
+	viper.SetDefault("db.sslMode", "disable")
+	viper.SetDefault("db.sslRootCert", "")
 	viper.SetDefault("db.logMode", false)
 	viper.SetDefault("db.maxRetries", 3)
+	viper.SetDefault("db.migrateOnStartup", true)
+
+	viper.SetDefault("operation.workers", 5)
+	viper.SetDefault("operation.queueSize", 100)
+	viper.SetDefault("operation.timeout", 1800)
+
+	viper.SetDefault("instance.liveLookup", false)
+	viper.SetDefault("instance.followInterval", 3600)
+	viper.SetDefault("instance.orphanSweepInterval", 0)
+
+	viper.SetDefault("bind.credentialMode", "instance")
+	viper.SetDefault("bind.credentialsVersion", 1)
+	viper.SetDefault("bind.tokenEndpoint", "")
+	viper.SetDefault("bind.credentialTemplate", "")
+
+	viper.SetDefault("reconcile.interval", 0)
+	viper.SetDefault("reconcile.repair", false)
+	viper.SetDefault("rotation.maxAge", 0)
+
+	viper.SetDefault("catalog.dynamic", false)
+	viper.SetDefault("catalog.refreshInterval", 300)
+	viper.SetDefault("catalog.listAPIs", false)
+	viper.SetDefault("catalog.apiPlan", false)
+	viper.SetDefault("catalog.subscriptionPlan", false)
 }
@@ -82,13 +82,37 @@ func TestSetDefaultConf(t *testing.T) {
 	testStringConf(t, "apim.storeEndpoint", "https://localhost:9443")
 	testStringConf(t, "apim.storeApplicationContext", "/api/am/store/v1/applications")
 	testStringConf(t, "apim.storeSubscriptionContext", "/api/am/store/v1/subscriptions")
+	testStringConf(t, "apim.storeThrottlingPolicyContext", "/api/am/store/v1/throttling-policies/application")
+	testStringConf(t, "apim.storeAPIContext", "/api/am/store/v1/apis")
+	testStringConf(t, "db.type", "mysql")
 	testStringConf(t, "db.host", "localhost")
 	testIntegerConf(t, "db.port", 3306)
 	testStringConf(t, "db.username", "root")
 	testStringConf(t, "db.password", "root123")
 	testStringConf(t, "db.database", "broker")
+	testStringConf(t, "db.sslMode", "disable")
+	testStringConf(t, "db.sslRootCert", "")
 	testBooleanConf(t, "db.logMode", false)
 	testIntegerConf(t, "db.maxRetries", 3)
+	testBooleanConf(t, "db.migrateOnStartup", true)
+	testIntegerConf(t, "operation.workers", 5)
+	testIntegerConf(t, "operation.queueSize", 100)
+	testIntegerConf(t, "operation.timeout", 1800)
+	testBooleanConf(t, "instance.liveLookup", false)
+	testIntegerConf(t, "instance.followInterval", 3600)
+	testIntegerConf(t, "instance.orphanSweepInterval", 0)
+	testStringConf(t, "bind.credentialMode", "instance")
+	testIntegerConf(t, "bind.credentialsVersion", 1)
+	testStringConf(t, "bind.tokenEndpoint", "")
+	testStringConf(t, "bind.credentialTemplate", "")
+	testIntegerConf(t, "reconcile.interval", 0)
+	testBooleanConf(t, "reconcile.repair", false)
+	testIntegerConf(t, "rotation.maxAge", 0)
+	testBooleanConf(t, "catalog.dynamic", false)
+	testIntegerConf(t, "catalog.refreshInterval", 300)
+	testBooleanConf(t, "catalog.listAPIs", false)
+	testBooleanConf(t, "catalog.apiPlan", false)
+	testBooleanConf(t, "catalog.subscriptionPlan", false)
 	viper.Reset()
 }
 
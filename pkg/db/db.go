@@ -26,14 +26,17 @@ import (
 
 	"github.com/wso2/openservicebroker-apim/pkg/model"
 
-	// mysql driver is blank import for grom
 	logPkg "log"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
+	// mysql, postgres and sqlite3 drivers are blank imports for grom
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 	"github.com/wso2/openservicebroker-apim/pkg/config"
 	"github.com/wso2/openservicebroker-apim/pkg/log"
@@ -41,10 +44,16 @@ import (
 
 const (
 	MySQL                   = "mysql"
+	PostgreSQL              = "postgres"
+	SQLite                  = "sqlite3"
+	SSLModeDisable          = "disable"
+	SSLModeRequire          = "require"
+	SSLModeVerifyFull       = "verify-full"
 	ErrMsgUnableToOpenDBCon = "unable to open a DB connect"
 )
 
 var (
+	dialect    string
 	url        string
 	logMode    bool
 	maxRetries int
@@ -67,17 +76,73 @@ func backOff(min, max time.Duration, attempt int) time.Duration {
 // Init initialize database parameters and open a DB connection.
 func Init(conf *config.DB) {
 	once.Do(func() {
-		url = conf.Username + ":" + conf.Password + "@tcp(" + conf.Host + ":" + strconv.Itoa(conf.Port) + ")/" +
-			conf.Database + "?charset=utf8"
+		var err error
+		url, err = dataSourceName(conf)
+		if err != nil {
+			log.HandleErrorAndExit(ErrMsgUnableToOpenDBCon, err)
+		}
+		dialect = conf.Type
 		logMode = conf.LogMode
 		maxRetries = conf.MaxRetries
-		err := connect()
+		err = connect()
 		if err != nil {
 			log.HandleErrorAndExit(ErrMsgUnableToOpenDBCon, err)
 		}
 	})
 }
 
+// Dialect returns the type of the database, MySQL, PostgreSQL or SQLite.
+func Dialect() string {
+	return dialect
+}
+
+// dataSourceName returns the data source name of the configured database and any error encountered.
+func dataSourceName(conf *config.DB) (string, error) {
+	switch conf.Type {
+	case MySQL:
+		dsn := conf.Username + ":" + conf.Password + "@tcp(" + conf.Host + ":" + strconv.Itoa(conf.Port) + ")/" +
+			conf.Database + "?charset=utf8&parseTime=true"
+		switch conf.SSLMode {
+		case "", SSLModeDisable:
+		case SSLModeRequire:
+			dsn += "&tls=skip-verify"
+		case SSLModeVerifyFull:
+			dsn += "&tls=true"
+		default:
+			return "", errors.New("unsupported SSL mode for mysql: " + conf.SSLMode)
+		}
+		if conf.SSLRootCert != "" {
+			return "", errors.New("sslRootCert is not supported for mysql, the system CA certificates are used")
+		}
+		return dsn, nil
+	case PostgreSQL:
+		params := []string{
+			"host=" + quoteDSNValue(conf.Host),
+			"port=" + strconv.Itoa(conf.Port),
+			"user=" + quoteDSNValue(conf.Username),
+			"password=" + quoteDSNValue(conf.Password),
+			"dbname=" + quoteDSNValue(conf.Database),
+		}
+		if conf.SSLMode != "" {
+			params = append(params, "sslmode="+quoteDSNValue(conf.SSLMode))
+		}
+		if conf.SSLRootCert != "" {
+			params = append(params, "sslrootcert="+quoteDSNValue(conf.SSLRootCert))
+		}
+		return strings.Join(params, " "), nil
+	case SQLite:
+		// the database is the file path, the foreign keys are enforced and the writers wait for each other
+		return conf.Database + "?_foreign_keys=1&_busy_timeout=5000", nil
+	default:
+		return "", errors.New("unsupported database type: " + conf.Type)
+	}
+}
+
+// quoteDSNValue quotes the given value of a PostgreSQL connection string parameter.
+func quoteDSNValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
 // CreateTable creates a table for the given model only if table already not exists.
 // Program will be closed if any error encountered.
 func CreateTable(e model.Entity) {
@@ -94,13 +159,32 @@ func CreateTable(e model.Entity) {
 	}
 }
 
+// MigrateTable creates the table of the given name with the columns of the given struct if it doesn't exist and adds
+// the missing columns and indexes otherwise. Existing columns are never changed or dropped. The struct describes the
+// table as of a migration, so a model which changes later must not be given. Returns any error encountered.
+func MigrateTable(table string, columns interface{}) error {
+	if err := db.Table(table).AutoMigrate(columns).Error; err != nil {
+		return errors.Wrapf(err, "unable to migrate the table: %s", table)
+	}
+	return nil
+}
+
+// AddColumns adds the columns of the given struct which are missing in the table of the given name. Returns an error
+// if the table doesn't exist or any other error encountered.
+func AddColumns(table string, columns interface{}) error {
+	if !db.HasTable(table) {
+		return errors.New("unable to add columns to the missing table: " + table)
+	}
+	return MigrateTable(table, columns)
+}
+
 // connect start a DB connection and returns any error occurred.
 func connect() error {
 	var ld = log.NewData().
 		Add("logMode", logMode)
 	var err error
 	for i := 0; i < maxRetries; i++ {
-		db, err = gorm.Open(MySQL, url)
+		db, err = gorm.Open(dialect, url)
 		if err == nil {
 			break
 		}
@@ -113,6 +197,10 @@ func connect() error {
 	if err != nil {
 		return errors.Wrap(err, "cannot initiate database connection")
 	}
+	if dialect == SQLite {
+		// SQLite allows a single writer, so the connection is shared to serialize the writes
+		db.DB().SetMaxOpenConns(1)
+	}
 	if logMode {
 		log.Debug("debug logs are enabled for Database", ld)
 		db.LogMode(logMode)
@@ -183,12 +271,34 @@ func RetrieveListByQuery(e model.Entity, query string, r interface{}) (bool, err
 	return true, nil
 }
 
+// DeleteByQuery deletes the entries of the table of the given entity matching the given query.
+// Returns the number of deleted entries and any error encountered.
+func DeleteByQuery(e model.Entity, query string, args ...interface{}) (int64, error) {
+	result := db.Table(e.TableName()).Where(query, args...).Delete(e)
+	return result.RowsAffected, result.Error
+}
+
+// UpdateByQuery sets the given column values of the entries of the table of the given entity matching the given query.
+// Returns the number of updated entries and any error encountered.
+func UpdateByQuery(e model.Entity, values map[string]interface{}, query string, args ...interface{}) (int64, error) {
+	result := db.Table(e.TableName()).Where(query, args...).Updates(values)
+	return result.RowsAffected, result.Error
+}
+
 // AddForeignKey adds a Foreign Key and returns any error encountered.
 // Ex: db.AddForeignKey(&User{}).AddForeignKey("city_id", "cities(id)", "RESTRICT", "RESTRICT").
 func AddForeignKey(e model.Entity, field string, dest string, onDelete string, onUpdate string) error {
+	if dialect == SQLite {
+		return addSQLiteForeignKey(e, field, dest, onDelete, onUpdate)
+	}
 	return db.Model(e).AddForeignKey(field, dest, onDelete, onUpdate).Error
 }
 
+// AddIndex adds an index on the given columns if the index doesn't exist and returns any error encountered.
+func AddIndex(e model.Entity, name string, columns ...string) error {
+	return db.Model(e).AddIndex(name, columns...).Error
+}
+
 // BulkInsert function does a bulk insert of a set of entities and returns any error encountered.
 func BulkInsert(entities []model.Entity) error {
 	tx := db.Begin()
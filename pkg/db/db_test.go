@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package db
+
+import (
+	"testing"
+
+	"github.com/wso2/openservicebroker-apim/pkg/config"
+)
+
+const ErrMsgTestIncorrectResult = "expected value: %v but then returned value: %v"
+
+func TestDataSourceName(t *testing.T) {
+	tests := []struct {
+		conf     config.DB
+		expected string
+		valid    bool
+	}{
+		{
+			config.DB{Type: MySQL, Host: "localhost", Port: 3306, Username: "root", Password: "pw", Database: "broker"},
+			"root:pw@tcp(localhost:3306)/broker?charset=utf8&parseTime=true",
+			true,
+		},
+		{
+			config.DB{Type: MySQL, Host: "db", Port: 3306, Username: "u", Password: "p", Database: "b",
+				SSLMode: SSLModeVerifyFull},
+			"u:p@tcp(db:3306)/b?charset=utf8&parseTime=true&tls=true",
+			true,
+		},
+		{
+			config.DB{Type: MySQL, Host: "db", Port: 3306, Username: "u", Password: "p", Database: "b",
+				SSLMode: SSLModeRequire},
+			"u:p@tcp(db:3306)/b?charset=utf8&parseTime=true&tls=skip-verify",
+			true,
+		},
+		{config.DB{Type: MySQL, SSLMode: "prefer"}, "", false},
+		{config.DB{Type: MySQL, SSLRootCert: "/ca.pem"}, "", false},
+		{
+			config.DB{Type: PostgreSQL, Host: "db", Port: 5432, Username: "u", Password: `it's\x`, Database: "b"},
+			`host='db' port=5432 user='u' password='it\'s\\x' dbname='b'`,
+			true,
+		},
+		{
+			config.DB{Type: PostgreSQL, Host: "db", Port: 5432, Username: "u", Password: "p", Database: "b",
+				SSLMode: SSLModeVerifyFull, SSLRootCert: "/certs/ca.pem"},
+			`host='db' port=5432 user='u' password='p' dbname='b' sslmode='verify-full' sslrootcert='/certs/ca.pem'`,
+			true,
+		},
+		{
+			config.DB{Type: SQLite, Database: "/data/broker.db"},
+			"/data/broker.db?_foreign_keys=1&_busy_timeout=5000",
+			true,
+		},
+		{config.DB{Type: "oracle"}, "", false},
+	}
+	for _, test := range tests {
+		dsn, err := dataSourceName(&test.conf)
+		if (err == nil) != test.valid {
+			t.Errorf(ErrMsgTestIncorrectResult, test.valid, err)
+		}
+		if dsn != test.expected {
+			t.Errorf(ErrMsgTestIncorrectResult, test.expected, dsn)
+		}
+	}
+}
+
+func TestQuoteDSNValue(t *testing.T) {
+	tests := map[string]string{
+		"":        "''",
+		"broker":  "'broker'",
+		"a b":     "'a b'",
+		"it's":    `'it\'s'`,
+		`back\sl`: `'back\\sl'`,
+		`\'`:      `'\\\''`,
+	}
+	for value, expected := range tests {
+		if result := quoteDSNValue(value); result != expected {
+			t.Errorf(ErrMsgTestIncorrectResult, expected, result)
+		}
+	}
+}
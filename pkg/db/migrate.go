@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/wso2/openservicebroker-apim/pkg/log"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+const (
+	// MigrationLockID is the ID of the lock held by the replica migrating the database.
+	MigrationLockID = "schema"
+	// MigrationLockTTL is the time after which the lock of an interrupted migration can be taken over.
+	MigrationLockTTL = 30 * time.Minute
+	// MigrationLockPollInterval is the time between the checks of a replica waiting for the migration lock.
+	MigrationLockPollInterval = 2 * time.Second
+	LogKeySchemaVersion       = "schema-version"
+	LogKeyMigrationOwner      = "migration-owner"
+)
+
+// Migration represents a versioned change of the database schema. The migrations are applied in the order of their
+// versions and each version is applied once. A migration isn't run in a transaction since MySQL commits schema
+// changes implicitly, so it must be safe to run again if it was interrupted.
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func() error
+}
+
+// Migrate applies the given migrations which are not recorded in the schema version table. The replicas migrating
+// the same database wait for each other with a lock in the database.
+// Returns any error encountered.
+func Migrate(migrations []Migration) error {
+	err := validateMigrations(migrations)
+	if err != nil {
+		return err
+	}
+	err = ensureTables(&model.SchemaVersion{}, &model.MigrationLock{})
+	if err != nil {
+		return err
+	}
+	owner := uuid.New().String()
+	err = lockMigrations(owner)
+	if err != nil {
+		return err
+	}
+	defer unlockMigrations(owner)
+
+	applied, err := appliedVersions()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		ld := log.NewData().Add(LogKeySchemaVersion, m.Version)
+		log.Info("applying the migration: "+m.Description, ld)
+		err = m.Migrate()
+		if err != nil {
+			return errors.Wrapf(err, "unable to apply the migration %d: %s", m.Version, m.Description)
+		}
+		err = Store(&model.SchemaVersion{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "unable to record the migration %d", m.Version)
+		}
+	}
+	for version := range applied {
+		if version > migrations[len(migrations)-1].Version {
+			log.Info(fmt.Sprintf("database schema version %d is newer than the broker", version), nil)
+		}
+	}
+	return nil
+}
+
+// PendingMigrations returns the versions of the given migrations which are not applied to the database and any error
+// encountered.
+func PendingMigrations(migrations []Migration) ([]int, error) {
+	applied := make(map[int]bool)
+	if db.HasTable(model.TableSchemaVersion) {
+		var err error
+		applied, err = appliedVersions()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var pending []int
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m.Version)
+		}
+	}
+	return pending, nil
+}
+
+// validateMigrations returns an error if the versions of the given migrations are not positive and increasing.
+func validateMigrations(migrations []Migration) error {
+	if len(migrations) == 0 {
+		return errors.New("no migrations are given")
+	}
+	previous := 0
+	for _, m := range migrations {
+		if m.Version <= previous {
+			return errors.New(fmt.Sprintf("migration version %d is out of order", m.Version))
+		}
+		previous = m.Version
+	}
+	return nil
+}
+
+// ensureTables creates the given tables if they don't exist. A table created meanwhile by another replica is not an
+// error. Returns any error encountered.
+func ensureTables(entities ...model.Entity) error {
+	for _, e := range entities {
+		if db.HasTable(e.TableName()) {
+			continue
+		}
+		err := db.CreateTable(e).Error
+		if err != nil && !db.HasTable(e.TableName()) {
+			return errors.Wrapf(err, "unable to create the table: %s", e.TableName())
+		}
+	}
+	return nil
+}
+
+// lockMigrations waits until the migration lock is stored for the given owner. A lock past its expiry is taken over.
+// Returns any error encountered.
+func lockMigrations(owner string) error {
+	ld := log.NewData().Add(LogKeyMigrationOwner, owner)
+	for {
+		existing := &model.MigrationLock{ID: MigrationLockID}
+		exists, err := Retrieve(existing)
+		if err != nil {
+			return errors.Wrap(err, "unable to retrieve the migration lock")
+		}
+		if exists && existing.ExpiresAt.After(time.Now()) {
+			log.Info("waiting for the migration of the replica "+existing.Owner, ld)
+			time.Sleep(MigrationLockPollInterval)
+			continue
+		}
+		if exists {
+			log.Info("taking over the expired migration lock of the replica "+existing.Owner, ld)
+			_, err = DeleteByQuery(&model.MigrationLock{}, "id = ? AND owner = ?", existing.ID, existing.Owner)
+			if err != nil {
+				return errors.Wrap(err, "unable to delete the expired migration lock")
+			}
+		}
+		err = Store(&model.MigrationLock{
+			ID:        MigrationLockID,
+			Owner:     owner,
+			ExpiresAt: time.Now().Add(MigrationLockTTL),
+		})
+		if err == nil {
+			return nil
+		}
+		// another replica may have stored the lock first
+		if exists, _ := Retrieve(&model.MigrationLock{ID: MigrationLockID}); !exists {
+			return errors.Wrap(err, "unable to store the migration lock")
+		}
+	}
+}
+
+// unlockMigrations removes the migration lock of the given owner.
+func unlockMigrations(owner string) {
+	_, err := DeleteByQuery(&model.MigrationLock{}, "id = ? AND owner = ?", MigrationLockID, owner)
+	if err != nil {
+		log.Error("unable to release the migration lock", err, log.NewData().Add(LogKeyMigrationOwner, owner))
+	}
+}
+
+// appliedVersions returns the versions recorded in the schema version table and any error encountered.
+func appliedVersions() (map[int]bool, error) {
+	var versions []model.SchemaVersion
+	_, err := RetrieveList(&model.SchemaVersion{}, &versions)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the schema versions")
+	}
+	applied := make(map[int]bool)
+	for _, v := range versions {
+		applied[v.Version] = true
+	}
+	return applied, nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package db
+
+import "testing"
+
+func TestValidateMigrations(t *testing.T) {
+	noop := func() error { return nil }
+	tests := []struct {
+		versions []int
+		valid    bool
+	}{
+		{[]int{1}, true},
+		{[]int{1, 2, 3}, true},
+		{[]int{1, 5, 10}, true},
+		{nil, false},
+		{[]int{0}, false},
+		{[]int{-1, 1}, false},
+		{[]int{1, 1}, false},
+		{[]int{2, 1}, false},
+		{[]int{1, 3, 2}, false},
+	}
+	for _, test := range tests {
+		var migrations []Migration
+		for _, v := range test.versions {
+			migrations = append(migrations, Migration{Version: v, Migrate: noop})
+		}
+		err := validateMigrations(migrations)
+		if (err == nil) != test.valid {
+			t.Errorf(ErrMsgTestIncorrectResult, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/openservicebroker-apim/pkg/model"
+)
+
+// addSQLiteForeignKey adds a foreign key to the table of the given entity if the field doesn't have one. SQLite
+// can't add a constraint to an existing table, so the table is rebuilt with the foreign key and its indexes are
+// re-created as described in https://www.sqlite.org/lang_altertable.html. Returns any error encountered.
+func addSQLiteForeignKey(e model.Entity, field, dest, onDelete, onUpdate string) error {
+	table := e.TableName()
+	exists, err := hasSQLiteForeignKey(table, field)
+	if err != nil || exists {
+		return err
+	}
+	var createSQL string
+	err = db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Row().Scan(&createSQL)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the definition of the table: %s", table)
+	}
+	indexSQLs, err := sqliteIndexes(table)
+	if err != nil {
+		return err
+	}
+	start, end := strings.Index(createSQL, "("), strings.LastIndex(createSQL, ")")
+	if start < 0 || end < start {
+		return errors.New("unexpected definition of the table: " + table)
+	}
+	rebuilt := table + "_rebuilt"
+	statements := append([]string{
+		fmt.Sprintf(`CREATE TABLE "%s" %s, FOREIGN KEY ("%s") REFERENCES %s ON DELETE %s ON UPDATE %s)`,
+			rebuilt, createSQL[start:end], field, dest, onDelete, onUpdate),
+		fmt.Sprintf(`INSERT INTO "%s" SELECT * FROM "%s"`, rebuilt, table),
+		fmt.Sprintf(`DROP TABLE "%s"`, table),
+		fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s"`, rebuilt, table),
+	}, indexSQLs...)
+
+	tx := db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "unable to rebuild the table: %s", table)
+		}
+	}
+	return tx.Commit().Error
+}
+
+// hasSQLiteForeignKey returns true if the given field of the given table has a foreign key and any error encountered.
+func hasSQLiteForeignKey(table, field string) (bool, error) {
+	rows, err := db.Raw(fmt.Sprintf(`PRAGMA foreign_key_list("%s")`, table)).Rows()
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, seq int
+		var parent, from string
+		var to, onUpdate, onDelete, match interface{}
+		if err := rows.Scan(&id, &seq, &parent, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return false, err
+		}
+		if from == field {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// sqliteIndexes returns the statements creating the explicit indexes of the given table and any error encountered.
+func sqliteIndexes(table string) ([]string, error) {
+	rows, err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL",
+		table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var statements []string
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, rows.Err()
+}
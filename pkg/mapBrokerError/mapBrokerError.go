@@ -48,6 +48,50 @@ type ErrorUnableToCreateAPIMResource struct{}
 type ErrorAPIMResourceAlreadyExists struct{}
 type ErrorEmptyAPIParameterSet struct{}
 type ErrorUnableToUpdateAPIMResource struct{}
+type ErrorUnableToStoreOperation struct{}
+type ErrorUnableToRetrieveOperation struct{}
+type ErrorOperationQueueFull struct{}
+type ErrorOperationInProgress struct{}
+type ErrorInvalidBindParameters struct{}
+type ErrorUnableToDeleteAPIMResource struct{}
+type ErrorInvalidPlan struct{}
+type ErrorUnableToUpdateSubscription struct{}
+type ErrorInvalidAPIParameter struct{}
+type ErrorInvalidVersionSelector struct{}
+type ErrorInvalidPlatformContext struct{}
+type ErrorInvalidAPIPlanParameters struct{}
+type ErrorInvalidLifecycleTransition struct {
+	From string
+	To   string
+}
+type ErrorUnableToStoreAPIInstance struct{}
+type ErrorInvalidSubscriptionPlanParameters struct{}
+type ErrorSubscriptionPlanChangeNotSupported struct{}
+type ErrorUnableToFindApplication struct {
+	AppName string
+}
+type ErrorUnableToStoreSubscriptionInstance struct{}
+type ErrorInvalidApplicationReference struct{}
+type ErrorInvalidKeyParameters struct {
+	Reason string
+}
+type ErrorUnableToSearchApplications struct{}
+type ErrorUnableToStoreSaga struct{}
+type ErrorUnableToLockInstance struct{}
+type ErrorUnableToRotateSecret struct{}
+type ErrorUnableToGenerateCredentials struct{}
+type ErrorApplicationAlreadyManaged struct {
+	AppName string
+}
+type ErrorUnableToRetrieveSubscriptionInstance struct{}
+type ErrorUnableToRetrieveAPIInstance struct{}
+type ErrorNoMatchingAPIVersion struct {
+	Selector string
+}
+type ErrorInvalidSubscriptionTier struct {
+	APIName string
+	Tier    string
+}
 type ErrorAPIMResourceDoesNotExist struct {
 	APIMResourceName string
 }
@@ -135,6 +179,126 @@ func (e *ErrorEmptyAPIParameterSet) Error() string {
 	return "No APIs Defined"
 }
 
+func (e *ErrorUnableToStoreOperation) Error() string {
+	return "unable to store the operation"
+}
+
+func (e *ErrorUnableToRetrieveOperation) Error() string {
+	return "unable to retrieve the operation"
+}
+
+func (e *ErrorOperationQueueFull) Error() string {
+	return "operation queue is full"
+}
+
+func (e *ErrorOperationInProgress) Error() string {
+	return "an operation is already in progress for the service instance"
+}
+
+func (e *ErrorInvalidBindParameters) Error() string {
+	return "invalid bind parameters"
+}
+
+func (e *ErrorUnableToDeleteAPIMResource) Error() string {
+	return "unable to delete the API-M resource"
+}
+
+func (e *ErrorInvalidPlan) Error() string {
+	return "invalid plan id"
+}
+
+func (e *ErrorInvalidAPIParameter) Error() string {
+	return "an API must be identified by id, context or name and version"
+}
+
+func (e *ErrorInvalidAPIPlanParameters) Error() string {
+	return "name, context, version, apiDefinition and endpointConfig of the API are required"
+}
+
+func (e *ErrorInvalidLifecycleTransition) Error() string {
+	return fmt.Sprintf("API lifecycle cannot be changed from %s to %s", e.From, e.To)
+}
+
+func (e *ErrorInvalidSubscriptionPlanParameters) Error() string {
+	return "apiName and appName of the subscription are required"
+}
+
+func (e *ErrorSubscriptionPlanChangeNotSupported) Error() string {
+	return "only the tier of the subscription can be changed"
+}
+
+func (e *ErrorUnableToFindApplication) Error() string {
+	return fmt.Sprintf("unable to find the Application %s", e.AppName)
+}
+
+func (e *ErrorUnableToStoreSaga) Error() string {
+	return "unable to journal the operation"
+}
+
+func (e *ErrorUnableToLockInstance) Error() string {
+	return "unable to lock the service instance"
+}
+
+func (e *ErrorUnableToRotateSecret) Error() string {
+	return "unable to rotate the consumer secret"
+}
+
+func (e *ErrorUnableToGenerateCredentials) Error() string {
+	return "unable to generate the bind credentials"
+}
+
+func (e *ErrorUnableToSearchApplications) Error() string {
+	return "unable to search Applications"
+}
+
+func (e *ErrorInvalidKeyParameters) Error() string {
+	return "invalid key parameters: " + e.Reason
+}
+
+func (e *ErrorInvalidApplicationReference) Error() string {
+	return "id or name of the Application is required"
+}
+
+func (e *ErrorApplicationAlreadyManaged) Error() string {
+	return fmt.Sprintf("the Application %s is already managed by a service instance", e.AppName)
+}
+
+func (e *ErrorUnableToStoreSubscriptionInstance) Error() string {
+	return "unable to store the subscription instance"
+}
+
+func (e *ErrorUnableToRetrieveSubscriptionInstance) Error() string {
+	return "unable to retrieve the subscription instance"
+}
+
+func (e *ErrorUnableToStoreAPIInstance) Error() string {
+	return "unable to store the API instance"
+}
+
+func (e *ErrorUnableToRetrieveAPIInstance) Error() string {
+	return "unable to retrieve the API instance"
+}
+
+func (e *ErrorInvalidPlatformContext) Error() string {
+	return "invalid platform context"
+}
+
+func (e *ErrorInvalidVersionSelector) Error() string {
+	return "invalid API version selector"
+}
+
+func (e *ErrorNoMatchingAPIVersion) Error() string {
+	return fmt.Sprintf("no API version matches %s", e.Selector)
+}
+
+func (e *ErrorUnableToUpdateSubscription) Error() string {
+	return "unable to update the subscription"
+}
+
+func (e *ErrorInvalidSubscriptionTier) Error() string {
+	return fmt.Sprintf("tier %s is not available for the API %s", e.Tier, e.APIName)
+}
+
 func returnInternalServerResponse(errMsg, loggerAction string) error {
 	return apiresponses.NewFailureResponse(errors.New(errMsg), http.StatusInternalServerError, loggerAction)
 }
@@ -195,6 +359,66 @@ func MapBrokerErrors(err error) error {
 		return returnInternalServerResponse("unable to update the API-M resource", ErrActionUpdateAPIMResource)
 	case *ErrorEmptyAPIParameterSet:
 		return returnBadRequestResponsee("No APIs Defined", "get service parameters")
+	case *ErrorInvalidBindParameters:
+		return returnBadRequestResponsee("invalid bind parameters", "get bind parameters")
+	case *ErrorUnableToDeleteAPIMResource:
+		return returnInternalServerResponse("unable to delete the API-M resource", "delete API-M resource")
+	case *ErrorInvalidPlan:
+		return returnBadRequestResponsee("invalid plan id", "get plan")
+	case *ErrorInvalidAPIParameter:
+		return returnBadRequestResponsee(err.Error(), "get service parameters")
+	case *ErrorInvalidAPIPlanParameters:
+		return returnBadRequestResponsee(err.Error(), "get API plan parameters")
+	case *ErrorInvalidLifecycleTransition:
+		return returnBadRequestResponsee(err.Error(), "change API lifecycle")
+	case *ErrorInvalidSubscriptionPlanParameters:
+		return returnBadRequestResponsee(err.Error(), "get subscription plan parameters")
+	case *ErrorSubscriptionPlanChangeNotSupported:
+		return returnBadRequestResponsee(err.Error(), "update subscription instance")
+	case *ErrorUnableToFindApplication:
+		return returnBadRequestResponsee(err.Error(), "search Application")
+	case *ErrorUnableToStoreSaga:
+		return returnInternalServerResponse("unable to journal the operation", "store saga")
+	case *ErrorUnableToLockInstance:
+		return returnInternalServerResponse("unable to lock the service instance", "lock instance")
+	case *ErrorUnableToRotateSecret:
+		return returnInternalServerResponse("unable to rotate the consumer secret", "regenerate consumer secret")
+	case *ErrorUnableToGenerateCredentials:
+		return returnInternalServerResponse("unable to generate the bind credentials", "generate bind credentials")
+	case *ErrorUnableToSearchApplications:
+		return returnInternalServerResponse("unable to search Applications", "search Applications")
+	case *ErrorInvalidKeyParameters:
+		return returnBadRequestResponsee(err.Error(), "get key parameters")
+	case *ErrorInvalidApplicationReference:
+		return returnBadRequestResponsee(err.Error(), "get Application parameter")
+	case *ErrorApplicationAlreadyManaged:
+		return returnBadRequestResponsee(err.Error(), "adopt Application")
+	case *ErrorUnableToStoreSubscriptionInstance:
+		return returnInternalServerResponse("unable to store the subscription instance", "store subscription instance")
+	case *ErrorUnableToRetrieveSubscriptionInstance:
+		return returnInternalServerResponse("unable to retrieve the subscription instance", "retrieve subscription instance")
+	case *ErrorUnableToStoreAPIInstance:
+		return returnInternalServerResponse("unable to store the API instance", "store API instance")
+	case *ErrorUnableToRetrieveAPIInstance:
+		return returnInternalServerResponse("unable to retrieve the API instance", "retrieve API instance")
+	case *ErrorInvalidPlatformContext:
+		return returnBadRequestResponsee(err.Error(), "get platform context")
+	case *ErrorInvalidVersionSelector:
+		return returnBadRequestResponsee(err.Error(), "get service parameters")
+	case *ErrorNoMatchingAPIVersion:
+		return returnBadRequestResponsee(err.Error(), "resolve API version")
+	case *ErrorUnableToUpdateSubscription:
+		return returnInternalServerResponse("unable to update the subscription", "update subscription")
+	case *ErrorInvalidSubscriptionTier:
+		return returnBadRequestResponsee(err.Error(), "validate subscription tier")
+	case *ErrorUnableToStoreOperation:
+		return returnInternalServerResponse("unable to store the operation", "store operation")
+	case *ErrorUnableToRetrieveOperation:
+		return returnInternalServerResponse("unable to retrieve the operation", "retrieve operation")
+	case *ErrorOperationQueueFull:
+		return apiresponses.NewFailureResponse(errors.New("operation queue is full"), http.StatusServiceUnavailable, "queue operation")
+	case *ErrorOperationInProgress:
+		return apiresponses.ErrConcurrentInstanceAccess
 	default:
 		return err
 	}
@@ -19,6 +19,11 @@
 // Package model handles the database models.
 package model
 
+import (
+	"strconv"
+	"time"
+)
+
 // Entity represents a table in the database.
 type Entity interface {
 	TableName() string
@@ -27,31 +32,159 @@ type Entity interface {
 
 // ServiceInstance represents the ServiceInstance model in the Database.
 type ServiceInstance struct {
+	ID        string `gorm:"primary_key;type:varchar(100)"`
+	ServiceID string `gorm:"type:varchar(100);not null"`
+	PlanID    string `gorm:"type:varchar(100);not null"`
+	// ThrottlingPolicy is the throttling policy of the plan the instance was created with or moved to
+	ThrottlingPolicy string `gorm:"type:varchar(100)"`
+	ApplicationID    string `gorm:"type:varchar(100);not null;unique;column:application_id"`
+	ApplicationName  string `gorm:"type:varchar(100);not null"`
+	SpaceID          string `gorm:"type:varchar(100);not null"`
+	OrgID            string `gorm:"type:varchar(100);not null"`
+	ConsumerKey      string `gorm:"type:varchar(100);not null"`
+	ConsumerSecret   string `gorm:"type:varchar(100);not null"`
+	ParameterHash    string `gorm:"type:varchar(100);not null"`
+	Platform         string `gorm:"type:varchar(50)"`
+	PlatformContext  string `gorm:"type:text"`
+	// Adopted is true if the Application existed in API-M before the instance was created
+	Adopted bool
+	// KeepApplication is true if the Application is left in API-M when the instance is deleted
+	KeepApplication bool
+	// Drift describes the differences to API-M found by the last reconciliation which are not repaired
+	Drift string `gorm:"type:text"`
+	// KeyParameters are the options of the generated keys in JSON, empty for the defaults
+	KeyParameters string `gorm:"type:text"`
+	// SecretRotatedAt is the time the consumer secret was last rotated or first checked for rotation
+	SecretRotatedAt *time.Time
+}
+
+// APIInstance represents a service instance of the API plan, which owns an API in API-M, in the database.
+type APIInstance struct {
 	ID              string `gorm:"primary_key;type:varchar(100)"`
-	ApplicationID   string `gorm:"type:varchar(100);not null;unique;column:application_id"`
+	ServiceID       string `gorm:"type:varchar(100);not null"`
+	PlanID          string `gorm:"type:varchar(100);not null"`
+	APIID           string `gorm:"type:varchar(100);not null;unique;column:api_id"`
+	APIName         string `gorm:"type:varchar(100);not null"`
+	APIVersion      string `gorm:"type:varchar(100);not null"`
+	LifecycleStatus string `gorm:"type:varchar(50)"`
+	Parameters      string `gorm:"type:text"`
+	ParameterHash   string `gorm:"type:varchar(100);not null"`
+	Platform        string `gorm:"type:varchar(50)"`
+	PlatformContext string `gorm:"type:text"`
+}
+
+// SubscriptionInstance represents a service instance of the subscription plan, which owns a single subscription of an
+// Application managed outside the broker, in the database.
+type SubscriptionInstance struct {
+	ID              string `gorm:"primary_key;type:varchar(100)"`
+	ServiceID       string `gorm:"type:varchar(100);not null"`
+	PlanID          string `gorm:"type:varchar(100);not null"`
+	SubscriptionID  string `gorm:"type:varchar(100);not null;unique"`
+	ApplicationID   string `gorm:"type:varchar(100);not null"`
 	ApplicationName string `gorm:"type:varchar(100);not null"`
-	SpaceID         string `gorm:"type:varchar(100);not null"`
-	OrgID           string `gorm:"type:varchar(100);not null"`
-	ConsumerKey     string `gorm:"type:varchar(100);not null"`
-	ConsumerSecret  string `gorm:"type:varchar(100);not null"`
+	APIID           string `gorm:"type:varchar(100);not null;column:api_id"`
+	APIName         string `gorm:"type:varchar(100);not null"`
+	APIVersion      string `gorm:"type:varchar(100);not null"`
+	Tier            string `gorm:"type:varchar(100)"`
+	Parameters      string `gorm:"type:text"`
 	ParameterHash   string `gorm:"type:varchar(100);not null"`
+	Platform        string `gorm:"type:varchar(50)"`
+	PlatformContext string `gorm:"type:text"`
 }
 
 // Subscription represents the Subscription model in the database.
 type Subscription struct {
-	ID            string `gorm:"primary_key;type:varchar(100);not null;unique"`
-	ApplicationID string `gorm:"type:varchar(100);not null"`
-	APIName       string `gorm:"type:varchar(100);not null"`
-	APIVersion    string `gorm:"type:varchar(100);not null"`
-	User          string `gorm:"type:varchar(100);not null"`
-	SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id"`
+	ID              string `gorm:"primary_key;type:varchar(100);not null;unique"`
+	ApplicationID   string `gorm:"type:varchar(100);not null"`
+	APIName         string `gorm:"type:varchar(100);not null"`
+	APIVersion      string `gorm:"type:varchar(100);not null"`
+	User            string `gorm:"type:varchar(100);not null"`
+	SVCInstanceID   string `gorm:"type:varchar(100);not null;column:svc_instance_id"`
+	Tier            string `gorm:"type:varchar(100)"`
+	APIID           string `gorm:"type:varchar(100);column:api_id"`
+	VersionSelector string `gorm:"type:varchar(100)"`
+	Follow          bool
 }
 
 // Bind represents the Bind model in the Database
 type Bind struct {
+	ID              string `gorm:"primary_key;type:varchar(100)"`
+	SVCInstanceID   string `gorm:"type:varchar(100);not null;column:svc_instance_id"`
+	PlatformAppID   string `gorm:"type:varchar(100)"`
+	Parameters      string `gorm:"type:text"`
+	CredentialMode  string `gorm:"type:varchar(20)"`
+	ApplicationID   string `gorm:"type:varchar(100);column:application_id"`
+	ApplicationName string `gorm:"type:varchar(100)"`
+	ConsumerKey     string `gorm:"type:varchar(100)"`
+	ConsumerSecret  string `gorm:"type:varchar(100)"`
+	// KeyParameters are the options of the keys of the bind Application in JSON, empty for the defaults
+	KeyParameters string `gorm:"type:text"`
+	// CredentialsVersion is the format of the credentials of the bind, "0" for binds created before the formats
+	// were versioned
+	CredentialsVersion int
+	// CredentialTemplate is the name of the template rendering the credentials, empty for the untemplated credentials
+	CredentialTemplate string `gorm:"type:varchar(100)"`
+}
+
+// Operation represents an asynchronous operation performed on a service instance.
+type Operation struct {
+	ID            string `gorm:"primary_key;type:varchar(100)"`
+	SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id;index"`
+	Type          string `gorm:"type:varchar(20);not null"`
+	State         string `gorm:"type:varchar(20);not null"`
+	Description   string `gorm:"type:varchar(1000)"`
+	RequestHash   string `gorm:"type:varchar(100)"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Saga represents a multi-step provision or update of a service instance whose API-M side effects are journaled.
+// A saga is removed once the operation is finished, so a remaining saga belongs to an interrupted operation.
+type Saga struct {
 	ID            string `gorm:"primary_key;type:varchar(100)"`
-	SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id"`
-	PlatformAppID string `gorm:"type:varchar(100)"`
+	SVCInstanceID string `gorm:"type:varchar(100);not null;column:svc_instance_id;index"`
+	Type          string `gorm:"type:varchar(20);not null"`
+	CreatedAt     time.Time
+}
+
+// SagaStep represents an API-M side effect of a saga. A step is stored as pending before the side effect and marked
+// as done with the ID of the affected resource afterwards.
+type SagaStep struct {
+	ID              string `gorm:"primary_key;type:varchar(100)"`
+	SagaID          string `gorm:"type:varchar(100);not null;index"`
+	Seq             int    `gorm:"not null"`
+	Action          string `gorm:"type:varchar(50);not null"`
+	State           string `gorm:"type:varchar(20);not null"`
+	ResourceID      string `gorm:"type:varchar(100)"`
+	ResourceName    string `gorm:"type:varchar(100)"`
+	ResourceVersion string `gorm:"type:varchar(100)"`
+	ApplicationID   string `gorm:"type:varchar(100);column:application_id"`
+	APIID           string `gorm:"type:varchar(100);column:api_id"`
+}
+
+// InstanceLock represents an operation holding a service instance. The lock is shared by all broker replicas through
+// the database and is released when the operation is finished. A lock past its expiry belongs to an interrupted
+// operation and can be taken over.
+type InstanceLock struct {
+	ID        string    `gorm:"primary_key;type:varchar(100)"`
+	Owner     string    `gorm:"type:varchar(100);not null"`
+	Operation string    `gorm:"type:varchar(20);not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+// SchemaVersion represents a migration applied to the database schema.
+type SchemaVersion struct {
+	Version     int    `gorm:"primary_key;auto_increment:false"`
+	Description string `gorm:"type:varchar(255)"`
+	AppliedAt   time.Time
+}
+
+// MigrationLock represents the broker replica migrating the database schema. A lock past its expiry belongs to an
+// interrupted migration and can be taken over.
+type MigrationLock struct {
+	ID        string    `gorm:"primary_key;type:varchar(100)"`
+	Owner     string    `gorm:"type:varchar(100);not null"`
+	ExpiresAt time.Time `gorm:"not null"`
 }
 
 func (ServiceInstance) TableName() string {
@@ -78,12 +211,115 @@ func (Subscription) TableName() string {
 	return TableSubscriptions
 }
 
+func (APIInstance) TableName() string {
+	return TableAPIInstances
+}
+
+func (a APIInstance) PrimaryKey() string {
+	return a.ID
+}
+
+func (SubscriptionInstance) TableName() string {
+	return TableSubscriptionInstances
+}
+
+func (s SubscriptionInstance) PrimaryKey() string {
+	return s.ID
+}
+
+func (Saga) TableName() string {
+	return TableSagas
+}
+
+func (s Saga) PrimaryKey() string {
+	return s.ID
+}
+
+func (SagaStep) TableName() string {
+	return TableSagaSteps
+}
+
+func (s SagaStep) PrimaryKey() string {
+	return s.ID
+}
+
+func (InstanceLock) TableName() string {
+	return TableInstanceLocks
+}
+
+func (l InstanceLock) PrimaryKey() string {
+	return l.ID
+}
+
+func (SchemaVersion) TableName() string {
+	return TableSchemaVersion
+}
+
+func (v SchemaVersion) PrimaryKey() string {
+	return strconv.Itoa(v.Version)
+}
+
+func (MigrationLock) TableName() string {
+	return TableMigrationLock
+}
+
+func (l MigrationLock) PrimaryKey() string {
+	return l.ID
+}
+
+func (Operation) TableName() string {
+	return TableOperations
+}
+
+func (o Operation) PrimaryKey() string {
+	return o.ID
+}
+
 const TableServiceInstance = "service_instances"
 
 const TableBind = "binds"
 
 const TableSubscriptions = "subscriptions"
 
+const TableOperations = "operations"
+
+const TableAPIInstances = "api_instances"
+
+const TableSubscriptionInstances = "subscription_instances"
+
+const TableSagas = "sagas"
+
+const TableSagaSteps = "saga_steps"
+
+const TableInstanceLocks = "instance_locks"
+
+const TableSchemaVersion = "schema_version"
+
+const TableMigrationLock = "schema_migration_lock"
+
+// Saga step actions and states.
+const (
+	SagaStepCreateApplication  = "create-application"
+	SagaStepCreateSubscription = "create-subscription"
+	SagaStepDeleteSubscription = "delete-subscription"
+	SagaStepCreateAPI          = "create-api"
+	SagaStepStatePending       = "pending"
+	SagaStepStateDone          = "done"
+)
+
+const (
+	OperationProvision   = "provision"
+	OperationUpdate      = "update"
+	OperationDeprovision = "deprovision"
+)
+
+// Operation states are aligned with the OSB last operation states.
+const (
+	OperationStateInProgress = "in progress"
+	OperationStateSucceeded  = "succeeded"
+	OperationStateFailed     = "failed"
+)
+
 const ServiceInstanceIDFieldName = "svc_instance_id"
 
 const ForeignKeyDestAppID = TableServiceInstance + "(" + ServiceInstanceIDFieldName + ")"
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,16 +46,20 @@ const (
 	GenerateAccessToken             = "Generating access Token"
 	DynamicClientRegMsg             = "Dynamic Client Reg"
 	RefreshTokenContext             = "Refresh token"
+	ClientCredentialsContext        = "Client credentials token"
 	Context                         = "/token"
 	UserName                        = "username"
 	Password                        = "password"
 	GrantPassword                   = "password"
 	GrantRefreshToken               = "refresh_token"
+	GrantClientCredentials          = "client_credentials"
 	GrantType                       = "grant_type"
 	Scope                           = "scope"
 	RefreshToken                    = "refresh_token"
 	ScopeSubscribe                  = "apim:subscribe"
 	ScopeAPIView                    = "apim:api_view"
+	ScopeAPICreate                  = "apim:api_create"
+	ScopeAPIPublish                 = "apim:api_publish"
 	LogKeyAT                        = "access-token"
 	LogKeyRT                        = "refresh-token"
 	LogKeyExpiresIn                 = "expires in"
@@ -319,3 +324,25 @@ func defaultClientRegBody() *DynamicClientRegReq {
 		SaasApp:     true,
 	}
 }
+
+// ClientCredentialsToken generates an access token for the given client with the client credentials grant from the
+// given token URL. The default scope is requested if no scopes are given.
+// Returns the token response and any error encountered.
+func ClientCredentialsToken(tokenURL, clientID, clientSecret string, scopes []string) (*Resp, error) {
+	data := url.Values{}
+	data.Set(GrantType, GrantClientCredentials)
+	if len(scopes) != 0 {
+		data.Set(Scope, strings.Join(scopes, " "))
+	}
+	req, err := client.CreateHTTPRequest(http.MethodPost, tokenURL, bytes.NewReader([]byte(data.Encode())))
+	if err != nil {
+		return nil, errors.Wrapf(err, ErrMsgUnableToCreateRequestBody, ClientCredentialsContext)
+	}
+	req.HTTPRequest().SetBasicAuth(clientID, clientSecret)
+	req.SetHeader(client.HTTPContentType, client.ContentTypeURLEncoded)
+	var resBody Resp
+	if err := client.Invoke(ClientCredentialsContext, req, &resBody, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &resBody, nil
+}
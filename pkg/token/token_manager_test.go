@@ -169,6 +169,60 @@ func testGenTokenSuccessFunc() func(t *testing.T) {
 	}
 }
 
+func TestClientCredentialsToken(t *testing.T) {
+	t.Run("success test case", testClientCredentialsTokenSuccessFunc())
+	t.Run("failure test case", testClientCredentialsTokenFailFunc())
+}
+
+func testClientCredentialsTokenFailFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		responder, err := httpmock.NewJsonResponder(http.StatusUnauthorized, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		httpmock.RegisterResponder(http.MethodPost, tokenEndpoint+Context, responder)
+
+		_, err = ClientCredentialsToken(tokenEndpoint+Context, "key", "secret", nil)
+		if err == nil {
+			t.Error("Expecting an error with code: " + strconv.Itoa(http.StatusUnauthorized))
+		}
+	}
+}
+
+func testClientCredentialsTokenSuccessFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodPost, tokenEndpoint+Context,
+			func(req *http.Request) (*http.Response, error) {
+				if err := req.ParseForm(); err != nil {
+					return nil, err
+				}
+				clientID, _, _ := req.BasicAuth()
+				if req.Form.Get(GrantType) != GrantClientCredentials || clientID != "key" {
+					return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+				}
+				return httpmock.NewJsonResponse(http.StatusOK, Resp{
+					AccessToken: dummyToken,
+					ExpiresIn:   expiresIn,
+				})
+			})
+
+		resp, err := ClientCredentialsToken(tokenEndpoint+Context, "key", "secret", []string{scope})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.AccessToken != dummyToken {
+			t.Errorf(ErrMsgTestIncorrectResult, dummyToken, resp.AccessToken)
+		}
+		if resp.ExpiresIn != expiresIn {
+			t.Errorf(ErrMsgTestIncorrectResult, expiresIn, resp.ExpiresIn)
+		}
+	}
+}
+
 func TestAccessTokenReqBody(t *testing.T) {
 	data := url.Values{}
 	data.Set(UserName, tmTest.UserName)
@@ -0,0 +1,249 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VersionLatest selects the highest released version.
+const VersionLatest = "latest"
+
+var (
+	ErrInvalidVersion         = errors.New("invalid semantic version")
+	ErrInvalidVersionSelector = errors.New("invalid version selector")
+	ErrNoMatchingVersion      = errors.New("no matching version found")
+)
+
+// Version represents a semantic version. Missing minor and patch numbers are treated as zero.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+}
+
+// versionConstraint returns true if the given version satisfies the constraint.
+type versionConstraint func(v Version) bool
+
+// ParseVersion parses the given semantic version. A leading "v" is allowed and build metadata is ignored.
+// Returns the parsed version and any error encountered.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.PreRelease = s[i+1:]
+		s = s[:i]
+	}
+	numbers, _, err := parseVersionNumbers(s)
+	if err != nil {
+		return v, err
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+// parseVersionNumbers parses up to three dot separated version numbers. A wildcard ("x", "X" or "*") ends the version.
+// Returns the numbers, the count of the numbers given before any wildcard and any error encountered.
+func parseVersionNumbers(s string) ([3]int, int, error) {
+	var numbers [3]int
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > 3 {
+		return numbers, 0, ErrInvalidVersion
+	}
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			return numbers, i, nil
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return numbers, 0, ErrInvalidVersion
+		}
+		numbers[i] = n
+	}
+	return numbers, len(parts), nil
+}
+
+// Compare returns -1, 0 or 1 if the version is lower than, equal to or higher than the given version.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.PreRelease == o.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case o.PreRelease == "":
+		return -1
+	case v.PreRelease < o.PreRelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// IsVersionSelector returns true if the given version is a selector rather than an exact version, i.e. "latest", a
+// term starting with an operator such as "^2.1.0" or ">=1.0.0 <2.0.0", or a version with a wildcard component such
+// as "2.x" or "*". Versions merely containing these characters, such as "v1-experimental", are exact versions.
+func IsVersionSelector(s string) bool {
+	if s == VersionLatest {
+		return true
+	}
+	for _, term := range strings.Fields(s) {
+		if strings.ContainsAny(term[:1], "^~<>=") {
+			return true
+		}
+		for _, part := range strings.Split(strings.TrimPrefix(term, "v"), ".") {
+			if part == "x" || part == "X" || part == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateVersionSelector returns an error if the given version selector is invalid.
+func ValidateVersionSelector(selector string) error {
+	_, err := parseVersionSelector(selector)
+	return err
+}
+
+// LatestMatchingVersion returns the highest released version among the given versions which matches the given
+// selector. Versions which are not semantic versions are ignored.
+// Returns the version and any error encountered.
+func LatestMatchingVersion(selector string, versions []string) (string, error) {
+	constraints, err := parseVersionSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	latest := ""
+	var latestVersion Version
+	for _, s := range versions {
+		v, err := ParseVersion(s)
+		if err != nil || v.PreRelease != "" || !matchesAll(constraints, v) {
+			continue
+		}
+		if latest == "" || v.Compare(latestVersion) > 0 {
+			latest = s
+			latestVersion = v
+		}
+	}
+	if latest == "" {
+		return "", ErrNoMatchingVersion
+	}
+	return latest, nil
+}
+
+func matchesAll(constraints []versionConstraint, v Version) bool {
+	for _, c := range constraints {
+		if !c(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersionSelector returns the constraints of the given space separated selector and any error encountered.
+func parseVersionSelector(selector string) ([]versionConstraint, error) {
+	if selector == VersionLatest {
+		return nil, nil
+	}
+	fields := strings.Fields(selector)
+	if len(fields) == 0 {
+		return nil, ErrInvalidVersionSelector
+	}
+	var constraints []versionConstraint
+	for _, field := range fields {
+		c, err := parseVersionConstraint(field)
+		if err != nil {
+			return nil, ErrInvalidVersionSelector
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// parseVersionConstraint returns the constraint of a single selector term and any error encountered.
+func parseVersionConstraint(term string) (versionConstraint, error) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(term, op) {
+			bound, err := ParseVersion(term[len(op):])
+			if err != nil {
+				return nil, err
+			}
+			return comparisonConstraint(op, bound), nil
+		}
+	}
+	if strings.HasPrefix(term, "^") || strings.HasPrefix(term, "~") {
+		lower, err := ParseVersion(term[1:])
+		if err != nil {
+			return nil, err
+		}
+		upper := Version{Major: lower.Major + 1}
+		if term[0] == '~' || lower.Major == 0 {
+			upper = Version{Major: lower.Major, Minor: lower.Minor + 1}
+		}
+		return func(v Version) bool {
+			return v.Compare(lower) >= 0 && v.Compare(upper) < 0
+		}, nil
+	}
+	numbers, count, err := parseVersionNumbers(strings.TrimPrefix(term, "v"))
+	if err != nil {
+		return nil, err
+	}
+	return func(v Version) bool {
+		given := [3]int{v.Major, v.Minor, v.Patch}
+		for i := 0; i < count; i++ {
+			if given[i] != numbers[i] {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func comparisonConstraint(op string, bound Version) versionConstraint {
+	return func(v Version) bool {
+		c := v.Compare(bound)
+		switch op {
+		case ">=":
+			return c >= 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case "<":
+			return c < 0
+		default:
+			return c == 0
+		}
+	}
+}
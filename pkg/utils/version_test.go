@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2019 WSO2 Inc. (http:www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http:www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package utils
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("v2.1.3-beta+build1")
+	if err != nil {
+		t.Error(err)
+	}
+	expected := Version{Major: 2, Minor: 1, Patch: 3, PreRelease: "beta"}
+	if v != expected {
+		t.Errorf(ErrMsgTestIncorrectResult, expected, v)
+	}
+	v, err = ParseVersion("2")
+	if err != nil {
+		t.Error(err)
+	}
+	expected = Version{Major: 2}
+	if v != expected {
+		t.Errorf(ErrMsgTestIncorrectResult, expected, v)
+	}
+	_, err = ParseVersion("2.a")
+	if err != ErrInvalidVersion {
+		t.Errorf(ErrMsgTestIncorrectResult, ErrInvalidVersion, err)
+	}
+}
+
+func TestIsVersionSelector(t *testing.T) {
+	for _, s := range []string{"latest", "2.x", "1.X.0", "v2.*", "^2.1.0", "~1.0", ">=1.0.0 <2.0.0", "=1.0.0", "*"} {
+		if !IsVersionSelector(s) {
+			t.Errorf(ErrMsgTestIncorrectResult, true, s)
+		}
+	}
+	for _, s := range []string{"1.0.0", "v1", "2", "v1-experimental", "nexus", "1.0.0-x", "2.0.0+xyz", "Latest", ""} {
+		if IsVersionSelector(s) {
+			t.Errorf(ErrMsgTestIncorrectResult, false, s)
+		}
+	}
+}
+
+func TestLatestMatchingVersion(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "2.0.0", "2.3.1", "2.10.0-beta", "3.0.0", "v1", "unversioned"}
+	tests := map[string]string{
+		"latest":         "3.0.0",
+		"2.x":            "2.3.1",
+		"1.*":            "1.2.0",
+		"^2.0.0":         "2.3.1",
+		"~1.0.0":         "1.0.0",
+		">=1.1.0 <2.0.0": "1.2.0",
+		"<=2.0.0 >1.0.0": "2.0.0",
+		"=1.0.0":         "1.0.0",
+	}
+	for selector, expected := range tests {
+		result, err := LatestMatchingVersion(selector, versions)
+		if err != nil {
+			t.Error(selector, err)
+		}
+		if result != expected {
+			t.Errorf(ErrMsgTestIncorrectResult, expected, result)
+		}
+	}
+	_, err := LatestMatchingVersion("4.x", versions)
+	if err != ErrNoMatchingVersion {
+		t.Errorf(ErrMsgTestIncorrectResult, ErrNoMatchingVersion, err)
+	}
+	_, err = LatestMatchingVersion("^a.b", versions)
+	if err != ErrInvalidVersionSelector {
+		t.Errorf(ErrMsgTestIncorrectResult, ErrInvalidVersionSelector, err)
+	}
+}
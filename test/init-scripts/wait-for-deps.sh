@@ -19,8 +19,8 @@
 
 set -e
 
-until nc -z mysql 3306; do
-  >&2 echo "Mysql is unavailable - sleeping"
+until nc -z "${APIM_BROKER_DB_HOST:-mysql}" "${APIM_BROKER_DB_PORT:-3306}"; do
+  >&2 echo "Database is unavailable - sleeping"
   sleep 1
 done
 